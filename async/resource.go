@@ -0,0 +1,170 @@
+// Package async provides components which render data loaded asynchronously,
+// e.g. fetched over the network.
+//
+// In a browser, blocking operations such as net/http requests must not be
+// performed from event listeners or Render, so they are run in their own
+// goroutine. Resource does so, renders a placeholder until they complete, and
+// cancels them if they are no longer needed:
+//
+// 	&async.Resource{
+// 		Key: userID,
+// 		Load: func(ctx context.Context) (interface{}, error) {
+// 			return fetchUser(ctx, userID)
+// 		},
+// 		Loading: func() vecty.ComponentOrHTML {
+// 			return elem.Span(vecty.Text("Loading…"))
+// 		},
+// 		Error: func(err error) vecty.ComponentOrHTML {
+// 			return elem.Span(vecty.Text("Failed to load user: " + err.Error()))
+// 		},
+// 		Done: func(data interface{}) vecty.ComponentOrHTML {
+// 			return &UserView{User: data.(*User)}
+// 		},
+// 	}
+package async
+
+import (
+	"context"
+	"sync"
+
+	"github.com/hexops/vecty"
+)
+
+// Resource is a component which loads data in its own goroutine, and renders
+// it once loaded.
+//
+// The data is loaded when the Resource is mounted, and loaded again whenever
+// its Key changes. Loading is cancelled, via the context passed to Load, when
+// the Resource is unmounted or its Key changes; a result returned afterwards is
+// discarded.
+type Resource struct {
+	vecty.Core
+
+	// Key identifies the data to load, and must be comparable. Whenever it
+	// changes, any pending load is cancelled and the data is loaded again.
+	//
+	// Changes to Load alone do not cause the data to be loaded again, as it is
+	// usually a new function literal on every render.
+	Key interface{} `vecty:"prop"`
+
+	// Load loads the data. It is run in its own goroutine, and should return
+	// early when ctx is cancelled.
+	Load func(ctx context.Context) (interface{}, error) `vecty:"prop"`
+
+	// Loading renders a placeholder whilst the data is loading. If nil,
+	// nothing is rendered.
+	Loading func() vecty.ComponentOrHTML `vecty:"prop"`
+
+	// Error renders the error returned by Load. If nil, nothing is rendered.
+	Error func(err error) vecty.ComponentOrHTML `vecty:"prop"`
+
+	// Done renders the data returned by Load.
+	Done func(data interface{}) vecty.ComponentOrHTML `vecty:"prop"`
+
+	load *load
+
+	// rerender is vecty.Rerender, unless replaced by tests.
+	rerender func(c vecty.Component)
+}
+
+// load is a single invocation of Resource.Load.
+type load struct {
+	key    interface{}
+	cancel context.CancelFunc
+
+	mu   sync.Mutex
+	done bool
+	data interface{}
+	err  error
+}
+
+// result returns the result of the load, if done.
+func (l *load) result() (done bool, data interface{}, err error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.done, l.data, l.err
+}
+
+// Mount implements the vecty.Mounter interface.
+func (r *Resource) Mount() {
+	r.start()
+}
+
+// Updated implements the vecty.Updater interface.
+func (r *Resource) Updated(prev vecty.Component) {
+	if r.load == nil || r.load.key != r.Key {
+		r.start()
+	}
+}
+
+// Unmount implements the vecty.Unmounter interface.
+func (r *Resource) Unmount() {
+	if r.load != nil {
+		r.load.cancel()
+	}
+}
+
+// Reload cancels any pending load and loads the data again, rendering the
+// Loading placeholder in the meantime.
+func (r *Resource) Reload() {
+	r.start()
+	r.rerenderResource()
+}
+
+// start cancels any pending load and starts loading the data.
+func (r *Resource) start() {
+	if r.load != nil {
+		r.load.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	l := &load{key: r.Key, cancel: cancel}
+	r.load = l
+	loadData := r.Load
+	go func() {
+		data, err := loadData(ctx)
+		if ctx.Err() != nil {
+			// Unmounted or superseded by another load.
+			return
+		}
+		l.mu.Lock()
+		l.done, l.data, l.err = true, data, err
+		l.mu.Unlock()
+		r.rerenderResource()
+	}()
+}
+
+// rerenderResource re-renders the component.
+func (r *Resource) rerenderResource() {
+	if r.rerender != nil {
+		r.rerender(r)
+		return
+	}
+	vecty.Rerender(r)
+}
+
+// Render implements the vecty.Component interface.
+func (r *Resource) Render() vecty.ComponentOrHTML {
+	if r.load == nil || r.load.key != r.Key {
+		// Loading starts once mounted or updated.
+		return r.renderLoading()
+	}
+	done, data, err := r.load.result()
+	switch {
+	case !done:
+		return r.renderLoading()
+	case err != nil:
+		if r.Error == nil {
+			return nil
+		}
+		return r.Error(err)
+	default:
+		return r.Done(data)
+	}
+}
+
+func (r *Resource) renderLoading() vecty.ComponentOrHTML {
+	if r.Loading == nil {
+		return nil
+	}
+	return r.Loading()
+}
@@ -0,0 +1,124 @@
+package async
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+)
+
+// stubRerender replaces vecty.Rerender for the given resource, returning a
+// channel receiving the components passed to it.
+func stubRerender(r *Resource) <-chan vecty.Component {
+	rerendered := make(chan vecty.Component, 10)
+	r.rerender = func(c vecty.Component) { rerendered <- c }
+	return rerendered
+}
+
+func TestResource(t *testing.T) {
+	loading, failed := elem.Span(), elem.Span()
+	results := make(chan error)
+	var loadedKeys []interface{}
+	r := &Resource{
+		Key: 1,
+		Load: func(ctx context.Context) (interface{}, error) {
+			return "data", <-results
+		},
+		Loading: func() vecty.ComponentOrHTML { return loading },
+		Error:   func(err error) vecty.ComponentOrHTML { return failed },
+		Done: func(data interface{}) vecty.ComponentOrHTML {
+			loadedKeys = append(loadedKeys, data)
+			return elem.Div()
+		},
+	}
+	rerendered := stubRerender(r)
+
+	if got := r.Render(); got != loading {
+		t.Fatalf("got %v before mount, want loading placeholder", got)
+	}
+	r.Mount()
+	if got := r.Render(); got != loading {
+		t.Fatalf("got %v whilst loading, want loading placeholder", got)
+	}
+	results <- nil
+	if c := <-rerendered; c != r {
+		t.Fatalf("got %v rerendered, want resource", c)
+	}
+	if _, ok := r.Render().(*vecty.HTML); !ok || len(loadedKeys) != 1 {
+		t.Fatal("got no data rendered once loaded")
+	}
+
+	// Updates without a key change do not reload.
+	r.Updated(r)
+	if got := r.Render(); got == loading {
+		t.Fatal("got loading placeholder after update with unchanged key")
+	}
+
+	// Changing the key reloads.
+	r.Key = 2
+	if got := r.Render(); got != loading {
+		t.Fatalf("got %v after key change, want loading placeholder", got)
+	}
+	r.Updated(r)
+	results <- errors.New("failed")
+	<-rerendered
+	if got := r.Render(); got != failed {
+		t.Fatalf("got %v, want error rendered", got)
+	}
+}
+
+func TestResource_cancel(t *testing.T) {
+	cancelled := make(chan struct{})
+	r := &Resource{
+		Key: 1,
+		Load: func(ctx context.Context) (interface{}, error) {
+			<-ctx.Done()
+			close(cancelled)
+			return nil, ctx.Err()
+		},
+		Done: func(data interface{}) vecty.ComponentOrHTML { return nil },
+	}
+	rerendered := stubRerender(r)
+	r.Mount()
+	r.Unmount()
+	<-cancelled
+	select {
+	case <-rerendered:
+		t.Fatal("got rerender after unmount")
+	default:
+	}
+}
+
+func TestResource_supersede(t *testing.T) {
+	first := make(chan struct{})
+	r := &Resource{
+		Key: "a",
+		Load: func(ctx context.Context) (interface{}, error) {
+			// The first load only completes once superseded.
+			<-ctx.Done()
+			close(first)
+			return "stale", nil
+		},
+		Done: func(data interface{}) vecty.ComponentOrHTML {
+			return elem.Div(vecty.Text(data.(string)))
+		},
+	}
+	rerendered := stubRerender(r)
+	r.Mount()
+
+	r.Key = "b"
+	r.Load = func(ctx context.Context) (interface{}, error) { return "b", nil }
+	r.Updated(r)
+	<-first
+	<-rerendered
+	if done, data, _ := r.load.result(); !done || data != "b" {
+		t.Fatalf("got data %v, want b", data)
+	}
+	select {
+	case <-rerendered:
+		t.Fatal("got rerender for superseded load")
+	default:
+	}
+}
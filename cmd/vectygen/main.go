@@ -0,0 +1,286 @@
+// Command vectygen generates Copy and CopyProps methods for the Vecty
+// components of a package, so that Vecty need not copy them using reflection.
+// This makes rendering faster, reduces binary size, and is required by TinyGo
+// (which cannot copy components using reflection).
+//
+// A component is any struct type which embeds vecty.Core or vecty.PureCore.
+// Methods which a component already declares are not generated.
+//
+// It is meant to be run via 'go generate', by adding the following to one of
+// the files of the package:
+//
+// 	//go:generate go run github.com/hexops/vecty/cmd/vectygen
+//
+// Usage:
+//
+// 	vectygen [-type T1,T2] [-output file] [dir]
+//
+// By default, methods are generated for all components of the package in the
+// current directory, into the file vecty_copy.go. Files are selected as when
+// building for GOOS=js and GOARCH=wasm.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const vectyPath = "github.com/hexops/vecty"
+
+var (
+	typeNames = flag.String("type", "", "comma-separated list of component type names; all components if empty")
+	output    = flag.String("output", "vecty_copy.go", "output file name, relative to dir")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("vectygen: ")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: vectygen [-type T1,T2] [-output file] [dir]")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	dir := "."
+	switch flag.NArg() {
+	case 0:
+	case 1:
+		dir = flag.Arg(0)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := build.Default
+	ctx.GOOS, ctx.GOARCH = "js", "wasm"
+	pkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+	outputPath := filepath.Join(dir, *output)
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		path := filepath.Join(dir, name)
+		if filepath.Clean(path) == filepath.Clean(outputPath) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			log.Fatal(err)
+		}
+		files = append(files, f)
+	}
+
+	var types []string
+	if *typeNames != "" {
+		types = strings.Split(*typeNames, ",")
+	}
+	src, err := generate(pkg.Name, files, types)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := ioutil.WriteFile(outputPath, src, 0o644); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// component is a component type to generate methods for.
+type component struct {
+	name  string
+	props []string
+
+	// hasCopy and hasCopyProps are whether the methods are already declared.
+	hasCopy, hasCopyProps bool
+}
+
+// generate returns the source of a file of the named package, declaring the
+// methods of the components declared by the given files. If types is not
+// empty, only the named components are considered.
+func generate(pkgName string, files []*ast.File, types []string) ([]byte, error) {
+	components, err := findComponents(files, types)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by \"vectygen\"; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&buf, "package %s\n\n", pkgName)
+	fmt.Fprintf(&buf, "import %q\n", vectyPath)
+	for _, c := range components {
+		if !c.hasCopy {
+			fmt.Fprintf(&buf, "\n// Copy implements the vecty.Copier interface.\n")
+			fmt.Fprintf(&buf, "func (c *%s) Copy() vecty.Component {\n", c.name)
+			fmt.Fprintf(&buf, "\tcpy := *c\n\treturn &cpy\n}\n")
+		}
+		if !c.hasCopyProps {
+			fmt.Fprintf(&buf, "\n// CopyProps implements the vecty.PropCopier interface.\n")
+			fmt.Fprintf(&buf, "func (c *%s) CopyProps(dst vecty.Component) {\n", c.name)
+			if len(c.props) == 0 {
+				fmt.Fprintf(&buf, "\t_ = dst.(*%s)\n", c.name)
+			} else {
+				fmt.Fprintf(&buf, "\td := dst.(*%s)\n", c.name)
+			}
+			for _, prop := range c.props {
+				fmt.Fprintf(&buf, "\td.%s = c.%s\n", prop, prop)
+			}
+			fmt.Fprintf(&buf, "}\n")
+		}
+	}
+	return format.Source(buf.Bytes())
+}
+
+// findComponents returns the components declared by the given files, in order
+// of declaration. If types is not empty, only the named components are
+// returned, and it is an error for one of them not to exist.
+func findComponents(files []*ast.File, types []string) ([]*component, error) {
+	wanted := make(map[string]bool, len(types))
+	for _, name := range types {
+		wanted[name] = true
+	}
+
+	var components []*component
+	byName := make(map[string]*component)
+	for _, f := range files {
+		vectyName := importName(f, vectyPath)
+		if vectyName == "" {
+			continue
+		}
+		for _, decl := range f.Decls {
+			gen, ok := decl.(*ast.GenDecl)
+			if !ok || gen.Tok != token.TYPE {
+				continue
+			}
+			for _, spec := range gen.Specs {
+				ts := spec.(*ast.TypeSpec)
+				st, ok := ts.Type.(*ast.StructType)
+				if !ok || !embedsCore(st, vectyName) {
+					continue
+				}
+				if len(wanted) > 0 && !wanted[ts.Name.Name] {
+					continue
+				}
+				c := &component{name: ts.Name.Name}
+				for _, field := range st.Fields.List {
+					if !isProp(field) {
+						continue
+					}
+					c.props = append(c.props, fieldNames(field)...)
+				}
+				components = append(components, c)
+				byName[c.name] = c
+			}
+		}
+	}
+	for _, name := range types {
+		if byName[name] == nil {
+			return nil, fmt.Errorf("no component named %s", name)
+		}
+	}
+
+	// Skip methods which are already declared.
+	for _, f := range files {
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+				continue
+			}
+			c := byName[typeName(fn.Recv.List[0].Type)]
+			if c == nil {
+				continue
+			}
+			switch fn.Name.Name {
+			case "Copy":
+				c.hasCopy = true
+			case "CopyProps":
+				c.hasCopyProps = true
+			}
+		}
+	}
+	return components, nil
+}
+
+// importName returns the name under which the file imports the given path, or
+// the empty string if it does not import it.
+func importName(f *ast.File, path string) string {
+	for _, imp := range f.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p != path {
+			continue
+		}
+		if imp.Name != nil {
+			return imp.Name.Name
+		}
+		return "vecty"
+	}
+	return ""
+}
+
+// embedsCore reports whether the struct embeds vecty.Core or vecty.PureCore,
+// where vecty is the name the Vecty package is imported under.
+func embedsCore(st *ast.StructType, vectyName string) bool {
+	for _, field := range st.Fields.List {
+		if len(field.Names) != 0 {
+			continue
+		}
+		sel, ok := field.Type.(*ast.SelectorExpr)
+		if !ok {
+			continue
+		}
+		if x, ok := sel.X.(*ast.Ident); ok && x.Name == vectyName && (sel.Sel.Name == "Core" || sel.Sel.Name == "PureCore") {
+			return true
+		}
+	}
+	return false
+}
+
+// isProp reports whether the struct field is tagged with `vecty:"prop"`.
+func isProp(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return false
+	}
+	return reflect.StructTag(tag).Get("vecty") == "prop"
+}
+
+// fieldNames returns the names of the struct field, which for embedded fields
+// is the name of their type.
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{typeName(field.Type)}
+	}
+	names := make([]string, len(field.Names))
+	for i, name := range field.Names {
+		names[i] = name.Name
+	}
+	return names
+}
+
+// typeName returns the name of the (possibly pointer or qualified) named
+// type expression.
+func typeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return typeName(t.X)
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"testing"
+)
+
+const src = `package components
+
+import (
+	v "github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+)
+
+type Item struct{ Title string }
+
+type ItemView struct {
+	v.Core
+	Index   int   ` + "`vecty:\"prop\"`" + `
+	*Item         ` + "`vecty:\"prop\"`" + `
+	A, B    string ` + "`vecty:\"prop\"`" + `
+	editing bool
+}
+
+type PageView struct {
+	v.PureCore
+	items []*Item
+}
+
+func (p *PageView) Copy() v.Component {
+	cpy := *p
+	return &cpy
+}
+
+type notComponent struct {
+	Core int
+}
+
+func (p *ItemView) Render() v.ComponentOrHTML { return elem.Div() }
+func (p *PageView) Render() v.ComponentOrHTML { return elem.Div() }
+`
+
+func TestGenerate(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "components.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := generate("components", []*ast.File{f}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `// Code generated by "vectygen"; DO NOT EDIT.
+
+package components
+
+import "github.com/hexops/vecty"
+
+// Copy implements the vecty.Copier interface.
+func (c *ItemView) Copy() vecty.Component {
+	cpy := *c
+	return &cpy
+}
+
+// CopyProps implements the vecty.PropCopier interface.
+func (c *ItemView) CopyProps(dst vecty.Component) {
+	d := dst.(*ItemView)
+	d.Index = c.Index
+	d.Item = c.Item
+	d.A = c.A
+	d.B = c.B
+}
+
+// CopyProps implements the vecty.PropCopier interface.
+func (c *PageView) CopyProps(dst vecty.Component) {
+	_ = dst.(*PageView)
+}
+`
+	if string(got) != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestGenerate_types(t *testing.T) {
+	f, err := parser.ParseFile(token.NewFileSet(), "components.go", src, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	components, err := findComponents([]*ast.File{f}, []string{"PageView"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(components) != 1 || components[0].name != "PageView" {
+		t.Fatalf("got %v components, want only PageView", components)
+	}
+
+	_, err = generate("components", []*ast.File{f}, []string{"notComponent"})
+	if want := "no component named notComponent"; err == nil || err.Error() != want {
+		t.Fatalf("got error %v want %q", err, want)
+	}
+}
@@ -0,0 +1,9 @@
+// Package vecty is a React-like library for building frontend web
+// applications in Go, compiled to WebAssembly or using TinyGo.
+//
+// Vecty may also be imported by programs compiled natively, such that packages
+// building upon it (e.g. component libraries) may be tested using a native
+// 'go test'. Components and HTML may then be created as usual, but there is no
+// browser to render them into: RenderBody, RenderInto, RenderIntoContainer and
+// RenderIntoShadow panic.
+package vecty
@@ -2,22 +2,58 @@ package vecty
 
 import (
 	"reflect"
+	"sync"
 )
 
-// batch renderer singleton
-var batch = &batchRenderer{idx: make(map[Component]int)}
-
 // Core implements the Context method of the Component interface, and is the
 // core/central struct which all Component implementations should embed.
 type Core struct {
 	prevRenderComponent Component
 	prevRender          ComponentOrHTML
 	mounted, unmounted  bool
+	root                *root
+	// dependencies are the Signals and Computeds read by the last Render.
+	dependencies []*dependents
 }
 
+// coreMu guards the fields of every Core which Rerender reads, as it may be
+// called from any goroutine whilst the component is being rendered. Only the
+// goroutine rendering writes them, so it may read them without locking.
+var coreMu sync.RWMutex
+
 // Context implements the Component interface.
 func (c *Core) Context() *Core { return c }
 
+// PureCore may be embedded by a Component instead of Core, in order to declare
+// that its rendering depends only on its `vecty:"prop"` fields (and its own
+// state, which it re-renders via Rerender when modified):
+//
+// 	type MyComponent struct {
+// 		vecty.PureCore
+// 		Title string `vecty:"prop"`
+// 	}
+//
+// When its parent is re-rendered, such a component skips rendering if all of
+// its properties are deeply equal (as per reflect.DeepEqual) to those of its
+// previous render, as if it implemented RenderSkipper accordingly. Components
+// which implement RenderSkipper themselves are not affected.
+//
+// As a consequence, properties must be treated as immutable: modifying the
+// value pointed to by a property, rather than passing a new one, is not
+// detected. Properties holding functions are never equal unless nil, and thus
+// always cause a render.
+type PureCore struct {
+	Core
+}
+
+// isPure implements the pure interface.
+func (c *PureCore) isPure() {}
+
+// pure is implemented by components which embed PureCore.
+type pure interface {
+	isPure()
+}
+
 // isMarkupOrChild implements MarkupOrChild
 func (c *Core) isMarkupOrChild() {}
 
@@ -73,6 +109,22 @@ type Copier interface {
 	Copy() Component
 }
 
+// PropCopier is an optional interface that a Component can implement in order
+// to copy its `vecty:"prop"` fields into another instance of the same type.
+// Vecty must internally copy properties, and it does so by either invoking the
+// CopyProps method of the Component or, if the component does not implement
+// the PropCopier interface, by using reflection.
+//
+// Both Copier and PropCopier may be generated, to avoid the cost of
+// reflection, using the vectygen tool:
+//
+// 	//go:generate go run github.com/hexops/vecty/cmd/vectygen
+type PropCopier interface {
+	// CopyProps copies the `vecty:"prop"` fields of the component into dst,
+	// which is of the same type.
+	CopyProps(dst Component)
+}
+
 // Mounter is an optional interface that a Component can implement in order
 // to receive component mount events.
 type Mounter interface {
@@ -89,6 +141,32 @@ type Unmounter interface {
 	Unmount()
 }
 
+// BeforeUpdater is an optional interface that a Component can implement in
+// order to receive an event before it is re-rendered.
+type BeforeUpdater interface {
+	// BeforeUpdate is called before a mounted component is re-rendered (unless
+	// its SkipRender method skips it), before its Render method is invoked and
+	// the DOM is modified. This is the place to capture DOM state, such as a
+	// scroll position, that the update may disturb.
+	//
+	// It is called with a copy of the Component made the last time its Render
+	// method was invoked.
+	BeforeUpdate(prev Component)
+}
+
+// Updater is an optional interface that a Component can implement in order to
+// receive an event after it has been re-rendered.
+type Updater interface {
+	// Updated is called after a mounted component has been re-rendered, once
+	// the changes have been applied to the DOM. This is the place to restore
+	// DOM state captured by BeforeUpdate, or to synchronize third-party
+	// widgets with the component.
+	//
+	// It is called with a copy of the Component made the last time its Render
+	// method was invoked before this re-render.
+	Updated(prev Component)
+}
+
 // Keyer is an optional interface that a Component can implement in order to
 // uniquely identify the component amongst its siblings. If implemented, all
 // siblings, both components and HTML, must also be keyed.
@@ -156,6 +234,21 @@ type HTML struct {
 	// lastRendered child tracks the last child that was rendered, across List
 	// boundaries.
 	lastRenderedChild *HTML
+	// controlled is the state of a controlled input, shared by all renders of
+	// the same DOM node. It is nil for uncontrolled elements.
+	controlled *controlledState
+	// ref is the Ref which refers to the DOM node of this element, if any.
+	ref *Ref
+	// enter and leave are the EnterLeave hooks of this element, if any.
+	enter func(h *HTML)
+	leave func(h *HTML, done func())
+}
+
+// controlledState is the state of a controlled input element (see Controlled).
+type controlledState struct {
+	// composing is whether the user is composing text via an input method
+	// editor.
+	composing bool
 }
 
 // Key implements the Keyer interface.
@@ -205,6 +298,9 @@ func (h *HTML) reconcile(prev *HTML) []Mounter {
 	case prev != nil && h.tag != "" && prev.tag != "" && h.tag == prev.tag && h.namespace == prev.namespace:
 		// Compatible element node
 		h.node = prev.node
+		if h.controlled != nil && prev.controlled != nil {
+			h.controlled = prev.controlled
+		}
 	default:
 		// Incompatible node, start fresh
 		if prev == nil {
@@ -220,7 +316,23 @@ func (h *HTML) reconcile(prev *HTML) []Mounter {
 		h.reconcileProperties(prev)
 	}
 
-	return h.reconcileChildren(prev)
+	if prev.ref != h.ref {
+		prev.releaseRef()
+	}
+	if h.ref != nil {
+		h.ref.node = h.node
+	}
+	if h.enter != nil && !h.node.Equal(prev.node) {
+		h.enter(h)
+	}
+
+	pendingMounts := h.reconcileChildren(prev)
+	if h.controlled != nil && h.tag == "select" {
+		// Select elements only accept the value of one of their options, which
+		// may not have existed when properties were reconciled.
+		h.reconcileLiveProperties("value", "selectedIndex")
+	}
+	return pendingMounts
 }
 
 // reconcileProperties updates properties/attributes/etc to match the current
@@ -254,16 +366,13 @@ func (h *HTML) reconcileProperties(prev *HTML) {
 	// Properties
 	for name, value := range h.properties {
 		var oldValue interface{}
-		switch name {
-		case "value":
-			oldValue = h.node.Get("value").String()
-		case "checked":
-			oldValue = h.node.Get("checked").Bool()
-		default:
+		if h.isLiveProperty(name) {
+			oldValue = h.liveProperty(name)
+		} else {
 			oldValue = prev.properties[name]
 		}
 		if value != oldValue {
-			h.node.Set(name, value)
+			h.setProperty(name, value)
 		}
 	}
 
@@ -310,6 +419,87 @@ func (h *HTML) reconcileProperties(prev *HTML) {
 	}
 }
 
+// releaseRef clears the Ref applied to this element, unless it has since been
+// applied to another element.
+func (h *HTML) releaseRef() {
+	if h.ref != nil && h.ref.node != nil && h.ref.node.Equal(h.node) {
+		h.ref.node = nil
+	}
+}
+
+// isLiveProperty reports whether the named property may be changed by the user
+// and must thus be compared against the live DOM node, rather than the previous
+// render, when reconciling.
+func (h *HTML) isLiveProperty(name string) bool {
+	switch name {
+	case "value", "checked":
+		return true
+	case "selected":
+		return h.tag == "option"
+	case "selectedIndex":
+		return h.tag == "select"
+	}
+	return false
+}
+
+// liveProperty returns the value of the named live property of the DOM node.
+func (h *HTML) liveProperty(name string) interface{} {
+	switch name {
+	case "value":
+		return h.node.Get("value").String()
+	case "selectedIndex":
+		return h.node.Get("selectedIndex").Int()
+	default:
+		return h.node.Get(name).Bool()
+	}
+}
+
+// reconcileLiveProperties sets the named live properties which differ from the
+// DOM node.
+func (h *HTML) reconcileLiveProperties(names ...string) {
+	for _, name := range names {
+		value, ok := h.properties[name]
+		if ok && value != h.liveProperty(name) {
+			h.setProperty(name, value)
+		}
+	}
+}
+
+// setProperty sets the named property of the DOM node, taking care not to
+// disturb the user when the element is a controlled input.
+func (h *HTML) setProperty(name string, value interface{}) {
+	if h.controlled == nil || name != "value" {
+		h.node.Set(name, value)
+		return
+	}
+	if h.controlled.composing {
+		// The input method editor owns the value until composition ends, after
+		// which the value is rendered again.
+		return
+	}
+	if !h.node.Call("matches", ":focus").Bool() {
+		h.node.Set(name, value)
+		return
+	}
+	// Setting the value moves the cursor to the end, so restore the selection.
+	// Some input types (e.g. number) have no selection, in which case it is
+	// null.
+	start, end := h.node.Get("selectionStart"), h.node.Get("selectionEnd")
+	h.node.Set(name, value)
+	if start == nil || end == nil {
+		return
+	}
+	length := h.node.Get("value").Get("length").Int()
+	h.node.Call("setSelectionRange", minInt(start.Int(), length), minInt(end.Int(), length))
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
 // removeProperties removes properties/attributes/etc that are no longer
 // present on the current element.
 func (h *HTML) removeProperties(prev *HTML) {
@@ -639,7 +829,8 @@ func (h *HTML) nextSibling() jsObject {
 }
 
 // removeChild removes the provided child element from this element, and
-// triggers unmount handlers.
+// triggers unmount handlers. If the child has a leave hook (see EnterLeave),
+// its removal from the DOM is delayed until the hook is done.
 func (h *HTML) removeChild(child *HTML) {
 	// If we're removing the current insert target, use the next
 	// sibling, if any.
@@ -650,6 +841,20 @@ func (h *HTML) removeChild(child *HTML) {
 	if child.node == nil {
 		return
 	}
+	if child.leave != nil {
+		// Delay removing the node until its leave transition is done.
+		node, removed := child.node, false
+		child.leave(child, func() {
+			if removed {
+				return
+			}
+			removed = true
+			if parent := node.Get("parentNode"); parent.Truthy() {
+				parent.Call("removeChild", node)
+			}
+		})
+		return
+	}
 	// Use the child's parent node here, in case our node is not a valid
 	// target by the time we're called.
 	child.node.Get("parentNode").Call("removeChild", child.node)
@@ -802,67 +1007,171 @@ func Text(text string, m ...MarkupOrChild) *HTML {
 // there is no guarantee that a calls to Rerender will map 1:1 with calls to
 // the Component's Render method. For example, two calls to Rerender may
 // result in only one call to the Component's Render method.
+//
+// Rerender is safe to call from any goroutine (e.g. one reading from a
+// WebSocket, or waiting on a timer): it only queues the Component, which is
+// then rendered by the render loop on the next animation frame. Access to the
+// Component's own state must still be synchronized by the caller, as Render may
+// read it concurrently.
+//
+// The Component is rendered with PriorityNormal; see RerenderWithPriority.
 func Rerender(c Component) {
+	rerender("Rerender", c, PriorityNormal)
+}
+
+// rerender queues the component on behalf of the named method.
+func rerender(method string, c Component, p Priority) {
 	if c == nil {
-		panic("vecty: Rerender illegally called with a nil Component argument")
-	}
-	if c.Context().prevRender == nil {
-		panic("vecty: Rerender invoked on Component that has never been rendered")
-	}
-	if c.Context().unmounted {
+		panic("vecty: " + method + " illegally called with a nil Component argument")
+	}
+	// The context is read under coreMu, as it may be written by the goroutine
+	// rendering the component concurrently.
+	coreMu.RLock()
+	ctx := c.Context()
+	rendered, unmounted, r := ctx.prevRender != nil, ctx.unmounted, ctx.root
+	coreMu.RUnlock()
+	if !rendered {
+		panic("vecty: " + method + " invoked on Component that has never been rendered")
+	}
+	if unmounted {
 		return
 	}
-	batch.add(c)
+	r.batch.add(c, p)
 }
 
-// batchRenderer handles component re-renders by queueing and deduplicating
-// them, to be rendered on the next animation frame (via requestAnimationFrame).
+// Priority is the priority with which a Component queued by
+// RerenderWithPriority is rendered, relative to other queued components.
+type Priority int
+
+const (
+	// PriorityBackground is for renders which may be deferred whilst there is
+	// more urgent work, e.g. a large list of search results which is updated
+	// as the user types a query. Such transitions are spread across frames as
+	// the frame budget allows.
+	PriorityBackground Priority = iota - 1
+
+	// PriorityNormal is the priority used by Rerender.
+	PriorityNormal
+
+	// PriorityUserBlocking is for renders which the user is waiting on, e.g.
+	// those reflecting text typed into an input. Such components are rendered
+	// first, and always in the next frame, regardless of the frame budget.
+	PriorityUserBlocking
+)
+
+// RerenderWithPriority is like Rerender, except that the Component is rendered
+// with the given priority: components queued with a higher priority are
+// rendered first, whilst those with a lower priority are the first to be
+// deferred to later frames if the frame budget is exceeded. Components with
+// the same priority are rendered in the order they were queued.
+//
+// If the Component is already queued, it is rendered with the higher of both
+// priorities:
+//
+// 	func (s *Search) onInput(e *vecty.Event) {
+// 		s.query = e.Target.Get("value").String()
+// 		vecty.RerenderWithPriority(s.input, vecty.PriorityUserBlocking)
+// 		vecty.RerenderWithPriority(s.results, vecty.PriorityBackground)
+// 	}
+func RerenderWithPriority(c Component, p Priority) {
+	rerender("RerenderWithPriority", c, p)
+}
+
+// renderLoop holds the configuration and statistics shared by the render loops
+// of all roots.
+var renderLoop = struct {
+	mu sync.Mutex
+	// scheduler schedules frames.
+	scheduler Scheduler
+	// budget limits the rendering done per frame.
+	budget FrameBudget
+	// stats instruments the render loops.
+	stats RenderStats
+}{
+	scheduler: AnimationFrameScheduler(),
+	budget:    DefaultFrameBudget,
+}
+
+// schedule schedules the given render loop via the configured Scheduler.
+func schedule(render func(startTime float64)) {
+	renderLoop.mu.Lock()
+	scheduler := renderLoop.scheduler
+	renderLoop.mu.Unlock()
+	scheduler.Schedule(render)
+}
+
+// rendering is the root being rendered, if any. It is only accessed by the
+// goroutine rendering.
+var rendering *root
+
+// batchRenderer handles the re-renders of the components of a root by queueing
+// and deduplicating them, to be rendered on the next frame (as determined by
+// the Scheduler).
 type batchRenderer struct {
-	// batch contains the list of pending components to render.
+	// mu guards the fields below, as components may be queued from any
+	// goroutine.
+	mu sync.Mutex
+	// batch contains the list of pending components to render, ordered by
+	// descending priority, then by the order they were queued in.
 	batch []Component
+	// priorities contains the priority of each pending component, in the same
+	// order as batch.
+	priorities []Priority
 	// idx maps components to batch indexes to allow dedup, retaining order.
 	idx map[Component]int
 	// scheduled tracks whether a batch has been scheduled for processing.
 	scheduled bool
+	// holds counts the initial renders in progress, during which no frames
+	// are scheduled.
+	holds int
 }
 
-// add a Component to the pending batch.
-func (b *batchRenderer) add(c Component) {
-	if i, ok := b.idx[c]; ok {
-		// Shift idx for delete.
-		for j, c := range b.batch[i+1:] {
-			b.idx[c] = i + j
-		}
-		// Delete previously queued render.
-		copy(b.batch[i:], b.batch[i+1:])
-		b.batch[len(b.batch)-1] = nil
-		b.batch = b.batch[:len(b.batch)-1]
+// add a Component to the pending batch with the given priority, or the priority
+// it is already queued with if higher.
+func (b *batchRenderer) add(c Component, p Priority) {
+	b.mu.Lock()
+	// Delete previously queued render.
+	if i, ok := b.idx[c]; ok && b.priorities[i] > p {
+		p = b.priorities[i]
 	}
-	// Append and index component.
-	b.batch = append(b.batch, c)
-	b.idx[c] = len(b.batch) - 1
+	b.dequeue(c)
+	b.enqueue(c, p)
 	// If we're not already scheduled for a render batch, request a render on
 	// the next frame.
-	if !b.scheduled {
+	needsFrame := !b.scheduled && b.holds == 0
+	if needsFrame {
 		b.scheduled = true
-		requestAnimationFrame(b.render)
+	}
+	b.mu.Unlock()
+	// The Scheduler is called without holding the lock, as it may render
+	// synchronously.
+	if needsFrame {
+		schedule(b.render)
 	}
 }
 
 // render the pending batch.
-// TODO(pdf): Add tests for time budget and multi-pass renders.
+// TODO(pdf): Add tests for multi-pass renders.
 func (b *batchRenderer) render(startTime float64) {
-	// If the batch is empty, mark as unscheduled, and stop render cycle.
-	if len(b.batch) == 0 {
+	// If the batch is empty (e.g. it was flushed), mark as unscheduled, and
+	// stop render cycle.
+	b.mu.Lock()
+	pending, priorities := b.drain()
+	if len(pending) == 0 {
 		b.scheduled = false
+	}
+	b.mu.Unlock()
+	renderLoop.mu.Lock()
+	renderLoop.stats.Frames++
+	if len(pending) == 0 {
+		renderLoop.stats.IdleFrames++
+	}
+	budget := renderLoop.budget
+	renderLoop.mu.Unlock()
+	if len(pending) == 0 {
 		return
 	}
 
-	// Drain the current batch.
-	pending := b.batch
-	b.batch = nil
-	b.idx = make(map[Component]int)
-
 	// Process batch.
 	for i, c := range pending {
 		// Skip unmounted components.
@@ -870,35 +1179,237 @@ func (b *batchRenderer) render(startTime float64) {
 			continue
 		}
 
-		// Check for remaining time budget, targeting 60fps (~16ms per frame).
-		if i > 0 {
-			elapsed := global().Get("performance").Call("now").Float() - startTime
-			budgetRemaining := (1000 / 60) - elapsed
+		// Check for remaining time budget, by default targeting 60fps (~16ms
+		// per frame). User-blocking renders are never deferred.
+		if i > 0 && budget.Duration > 0 && priorities[i] < PriorityUserBlocking {
+			elapsed := now() - startTime
+			budgetRemaining := milliseconds(budget.Duration) - elapsed
 			avgRenderTime := elapsed / float64(i)
-			// If the budget remaining is less than 2 times the average
+			// If the budget remaining is less than Headroom times the average
 			// Component render time, push the remainder of the batch to the
 			// next frame.
-			if budgetRemaining < avgRenderTime*2 {
-				b.batch = pending[i:]
-				for i, c := range b.batch {
-					b.idx[c] = i
-				}
+			if budgetRemaining < avgRenderTime*budget.Headroom {
+				b.requeue(pending[i:], priorities[i:])
 				break
 			}
 		}
 
-		// Perform render.
-		prevHTML := extractHTML(c.Context().prevRender)
-		nextHTML, skip, pendingMounts := renderComponent(c, c)
-		if skip {
-			continue
-		}
+		b.renderOne(c)
+	}
+
+	// Schedule next frame, if there is anything left to render (including
+	// components queued whilst rendering), or else stop the render cycle so
+	// that no frames are requested whilst idle.
+	b.mu.Lock()
+	if len(b.batch) == 0 {
+		b.scheduled = false
+		b.mu.Unlock()
+		return
+	}
+	b.mu.Unlock()
+	schedule(b.render)
+}
+
+// hold prevents frames from being scheduled until release is called, so that
+// components queued during an initial render are only rendered after it
+// completes.
+func (b *batchRenderer) hold() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.holds++
+}
+
+// release undoes a call to hold, scheduling a frame if components were queued
+// in the meantime.
+func (b *batchRenderer) release() {
+	b.mu.Lock()
+	b.holds--
+	needsFrame := b.holds == 0 && !b.scheduled && len(b.batch) > 0
+	if needsFrame {
+		b.scheduled = true
+	}
+	b.mu.Unlock()
+	if needsFrame {
+		schedule(b.render)
+	}
+}
+
+// RenderStats instruments the render loops (one per root), which render
+// components queued by Rerender.
+type RenderStats struct {
+	// Frames is the number of frames in which a render loop ran.
+	Frames uint64
+
+	// IdleFrames is the number of frames in which a render loop ran but
+	// found nothing to render, e.g. because the queued components were
+	// rendered by Flush in the meantime.
+	IdleFrames uint64
+}
+
+// ReadRenderStats returns statistics about the render loop. Frames are only
+// requested whilst components are queued, so an idle application does not
+// wake up to render: Frames stays constant.
+func ReadRenderStats() RenderStats {
+	renderLoop.mu.Lock()
+	defer renderLoop.mu.Unlock()
+	return renderLoop.stats
+}
+
+// drain empties the pending batch, returning its components and their
+// priorities. It must be called with b.mu held.
+//
+// Rendering happens without holding the lock, so that components may be queued
+// meanwhile (including by Render itself).
+func (b *batchRenderer) drain() ([]Component, []Priority) {
+	pending, priorities := b.batch, b.priorities
+	b.batch, b.priorities = nil, nil
+	b.idx = make(map[Component]int)
+	return pending, priorities
+}
+
+// renderOne re-renders the given component, and applies the changes to the
+// DOM.
+func (b *batchRenderer) renderOne(c Component) {
+	if c.Context().unmounted {
+		return
+	}
+	rendering = c.Context().root
+	prevHTML := extractHTML(c.Context().prevRender)
+	nextHTML, skip, pendingMounts := renderComponent(c, c)
+	if !skip {
 		replaceNode(nextHTML.node, prevHTML.node)
-		mount(pendingMounts...)
 	}
+	rendering = nil
+	mount(pendingMounts...)
+}
+
+// remove removes the component from the pending batch, if queued.
+func (b *batchRenderer) remove(c Component) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.dequeue(c)
+}
+
+// dequeue removes the component from the pending batch, if queued. It must be
+// called with b.mu held.
+func (b *batchRenderer) dequeue(c Component) {
+	i, ok := b.idx[c]
+	if !ok {
+		return
+	}
+	// Shift idx for delete.
+	for j, c := range b.batch[i+1:] {
+		b.idx[c] = i + j
+	}
+	copy(b.batch[i:], b.batch[i+1:])
+	b.batch[len(b.batch)-1] = nil
+	b.batch = b.batch[:len(b.batch)-1]
+	copy(b.priorities[i:], b.priorities[i+1:])
+	b.priorities = b.priorities[:len(b.priorities)-1]
+	delete(b.idx, c)
+}
+
+// enqueue inserts the component, which must not be queued, into the pending
+// batch after all components of the same or higher priority. It must be called
+// with b.mu held.
+func (b *batchRenderer) enqueue(c Component, p Priority) {
+	i := len(b.batch)
+	for i > 0 && b.priorities[i-1] < p {
+		i--
+	}
+	b.batch = append(b.batch, nil)
+	b.priorities = append(b.priorities, 0)
+	// Shift idx for insert.
+	for j, c := range b.batch[i : len(b.batch)-1] {
+		b.idx[c] = i + j + 1
+	}
+	copy(b.batch[i+1:], b.batch[i:])
+	copy(b.priorities[i+1:], b.priorities[i:])
+	b.batch[i], b.priorities[i] = c, p
+	b.idx[c] = i
+}
+
+// maxFlushPasses is the number of times Flush renders the pending batch before
+// concluding that components are endlessly re-rendering each other.
+const maxFlushPasses = 100
 
-	// Schedule next frame.
-	requestAnimationFrame(b.render)
+// Flush immediately renders all components queued by Rerender (in all roots),
+// rather than waiting for the next animation frame, and applies the changes to
+// the DOM. Components queued whilst flushing (e.g. by Mount) are rendered too.
+//
+// This is useful when the DOM must reflect a state change right away, e.g. to
+// measure layout or to focus a newly rendered element, and in tests.
+//
+// Unlike Rerender, Flush renders on the calling goroutine, and must not be
+// called from within Render.
+func Flush() {
+	if rendering != nil {
+		panic("vecty: Flush illegally called during Render")
+	}
+	for pass := 0; ; pass++ {
+		if pass == maxFlushPasses {
+			panic("vecty: Flush exceeded maximum number of render passes (do components Rerender each other endlessly?)")
+		}
+		flushed := false
+		for _, r := range renderedRoots() {
+			r.batch.mu.Lock()
+			pending, _ := r.batch.drain()
+			r.batch.mu.Unlock()
+			for _, c := range pending {
+				r.batch.renderOne(c)
+			}
+			flushed = flushed || len(pending) > 0
+		}
+		if !flushed {
+			// The scheduled frames, if any, will find nothing to render.
+			return
+		}
+	}
+}
+
+// RerenderSync is like Rerender, except that it immediately renders the given
+// Component, rather than waiting for the next animation frame, and applies the
+// changes to the DOM. Other components queued by Rerender are left queued.
+//
+// Like Flush, RerenderSync renders on the calling goroutine, and must not be
+// called from within Render.
+func RerenderSync(c Component) {
+	if c == nil {
+		panic("vecty: RerenderSync illegally called with a nil Component argument")
+	}
+	if c.Context().prevRender == nil {
+		panic("vecty: RerenderSync invoked on Component that has never been rendered")
+	}
+	if rendering != nil {
+		panic("vecty: RerenderSync illegally called during Render")
+	}
+	b := c.Context().root.batch
+	b.remove(c)
+	b.renderOne(c)
+}
+
+// requeue puts components which could not be rendered in this frame back into
+// the pending batch with the given priorities, ahead of any of the same
+// priority queued whilst rendering.
+func (b *batchRenderer) requeue(remaining []Component, priorities []Priority) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	queued, queuedPriorities, queuedIdx := b.batch, b.priorities, b.idx
+	b.batch, b.priorities = nil, nil
+	b.idx = make(map[Component]int, len(remaining)+len(queued))
+	for i, c := range remaining {
+		p := priorities[i]
+		if j, ok := queuedIdx[c]; ok && queuedPriorities[j] > p {
+			p = queuedPriorities[j]
+		}
+		b.enqueue(c, p)
+	}
+	for i, c := range queued {
+		if _, ok := b.idx[c]; ok {
+			continue
+		}
+		b.enqueue(c, queuedPriorities[i])
+	}
 }
 
 // extractHTML returns the *HTML from a ComponentOrHTML.
@@ -951,13 +1462,18 @@ func copyComponent(c Component) Component {
 }
 
 // copyProps copies all struct fields from src to dst that are tagged with
-// `vecty:"prop"`.
+// `vecty:"prop"`, using the CopyProps method of src if it implements the
+// PropCopier interface.
 //
 // If src and dst are different types or non-pointers, copyProps panics.
 func copyProps(src, dst Component) {
 	if src == dst {
 		return
 	}
+	if pc, ok := src.(PropCopier); ok {
+		pc.CopyProps(dst)
+		return
+	}
 	s := reflect.ValueOf(src)
 	d := reflect.ValueOf(dst)
 	if s.Type() != d.Type() {
@@ -968,7 +1484,7 @@ func copyProps(src, dst Component) {
 	}
 	for i := 0; i < s.Elem().NumField(); i++ {
 		sf := s.Elem().Field(i)
-		if s.Elem().Type().Field(i).Tag.Get("vecty") == "prop" {
+		if isProp(s.Elem().Type().Field(i)) {
 			df := d.Elem().Field(i)
 			if sf.Type() != df.Type() {
 				panic("vecty: internal error (should never be possible, struct types are identical)")
@@ -978,6 +1494,35 @@ func copyProps(src, dst Component) {
 	}
 }
 
+// propsEqual reports whether all struct fields of a and b that are tagged with
+// `vecty:"prop"` are deeply equal.
+//
+// If a and b are different types or non-pointers, propsEqual panics.
+func propsEqual(a, b Component) bool {
+	av := reflect.ValueOf(a)
+	bv := reflect.ValueOf(b)
+	if av.Type() != bv.Type() {
+		panic("vecty: internal error (attempted to compare properties of incompatible structs)")
+	}
+	if av.Kind() != reflect.Ptr {
+		panic("vecty: internal error (attempted to compare properties of non-pointer)")
+	}
+	for i := 0; i < av.Elem().NumField(); i++ {
+		if !isProp(av.Elem().Type().Field(i)) {
+			continue
+		}
+		if !reflect.DeepEqual(av.Elem().Field(i).Interface(), bv.Elem().Field(i).Interface()) {
+			return false
+		}
+	}
+	return true
+}
+
+// isProp reports whether the struct field is tagged with `vecty:"prop"`.
+func isProp(f reflect.StructField) bool {
+	return f.Tag.Get("vecty") == "prop"
+}
+
 // render handles rendering the next child into HTML. If skip is returned,
 // the component's SkipRender method has signaled to skip rendering.
 //
@@ -1012,6 +1557,7 @@ func render(next, prev ComponentOrHTML) (nextHTML *HTML, skip bool, pendingMount
 func renderComponent(next Component, prev ComponentOrHTML) (nextHTML *HTML, skip bool, pendingMounts []Mounter) {
 	// If we had a component last render, and it's of compatible type, operate
 	// on the previous instance.
+	fromParent := next != prev
 	if prevComponent, ok := prev.(Component); ok && sameType(next, prevComponent) {
 		// Copy `vecty:"prop"` fields from the newly rendered component (next)
 		// into the persistent component instance (prev) so that it is aware of
@@ -1034,10 +1580,35 @@ func renderComponent(next Component, prev ComponentOrHTML) (nextHTML *HTML, skip
 				return nil, true, nil
 			}
 		}
+	} else if _, ok := next.(pure); ok && fromParent {
+		// Pure components only change when their properties do.
+		prevRenderComponent := next.Context().prevRenderComponent
+		if prevRenderComponent != nil && propsEqual(next, prevRenderComponent) {
+			return nil, true, nil
+		}
+	}
+
+	// A component which was rendered before, and not unmounted since, is being
+	// updated rather than rendered for the first time. Whether it is mounted
+	// cannot tell, as only Mounters are marked as such.
+	var updated Mounter
+	if next.Context().prevRender != nil && !next.Context().unmounted {
+		prevRenderComponent := next.Context().prevRenderComponent
+		if bu, ok := next.(BeforeUpdater); ok {
+			bu.BeforeUpdate(prevRenderComponent)
+		}
+		if u, ok := next.(Updater); ok {
+			updated = updateEvent{u, prevRenderComponent}
+		}
 	}
 
-	// Render the component into HTML, handling nil renders.
-	nextRender := next.Render()
+	// Render the component into HTML, handling nil renders. Any Signal read
+	// during Render will re-render the component when it changes.
+	var nextRender ComponentOrHTML
+	untrackDependencies(next)
+	trackDependencies(componentDependent{next}, func() {
+		nextRender = next.Render()
+	})
 	prevRender := next.Context().prevRender
 	if nextRender == nil {
 		// nil renders are translated into noscript tags.
@@ -1069,14 +1640,32 @@ func renderComponent(next Component, prev ComponentOrHTML) (nextHTML *HTML, skip
 	if m != nil {
 		pendingMounts = append(pendingMounts, m)
 	}
+	if updated != nil {
+		pendingMounts = append(pendingMounts, updated)
+	}
 
 	// Update the context to consider this render.
+	coreMu.Lock()
+	next.Context().root = rendering
 	next.Context().prevRender = nextRender
 	next.Context().prevRenderComponent = copyComponent(next)
 	next.Context().unmounted = false
+	coreMu.Unlock()
 	return nextHTML, false, pendingMounts
 }
 
+// updateEvent is a pending Updated event, which is dispatched alongside mount
+// events once the DOM has been modified.
+type updateEvent struct {
+	u    Updater
+	prev Component
+}
+
+// Mount implements the Mounter interface.
+func (e updateEvent) Mount() {
+	e.u.Updated(e.prev)
+}
+
 // mountUnmount determines whether a mount or unmount event should occur,
 // actions unmounts recursively if appropriate, and returns either a Mounter,
 // or nil.
@@ -1119,8 +1708,10 @@ func mount(pendingMounts ...Mounter) {
 			if c.Context().mounted {
 				continue
 			}
+			coreMu.Lock()
 			c.Context().mounted = true
 			c.Context().unmounted = false
+			coreMu.Unlock()
 		}
 		mounter.Mount()
 	}
@@ -1133,8 +1724,11 @@ func unmount(e ComponentOrHTML) {
 		if c.Context().unmounted {
 			return
 		}
+		coreMu.Lock()
 		c.Context().unmounted = true
 		c.Context().mounted = false
+		coreMu.Unlock()
+		untrackDependencies(c)
 		if prevRenderComponent, ok := c.Context().prevRender.(Component); ok {
 			unmount(prevRenderComponent)
 		}
@@ -1151,6 +1745,7 @@ func unmount(e ComponentOrHTML) {
 		for _, child := range h.children {
 			unmount(child)
 		}
+		h.releaseRef()
 	}
 
 	if u, ok := e.(Unmounter); ok {
@@ -1186,7 +1781,7 @@ func requestAnimationFrame(callback func(float64)) int {
 // 	select{} // run Go forever
 //
 func RenderBody(body Component) {
-	target := global().Get("document").Call("querySelector", "body")
+	target := document().Call("querySelector", "body")
 	err := renderIntoNode("RenderBody", target, body)
 	if err != nil {
 		panic(err)
@@ -1223,25 +1818,82 @@ func (e InvalidTargetError) Error() string {
 // found, an error of type InvalidTargetError is returned.
 //
 // If the Component's Render method does not return an element of the same type,
-// an error of type ElementMismatchError is returned.
+// an error of type ElementMismatchError is returned. To render a component into
+// an element of a different type, use RenderIntoContainer instead.
 func RenderInto(selector string, c Component) error {
-	target := global().Get("document").Call("querySelector", selector)
+	target := document().Call("querySelector", selector)
 	return renderIntoNode("RenderInto", target, c)
 }
 
+// RenderIntoContainer renders the given component as the content of the
+// existing HTML element found by the CSS selector (e.g. "#app"), replacing any
+// existing children of the element (such as a loading indicator).
+//
+// Unlike RenderInto, the element itself is kept, so the Component's Render
+// method may return any element: for example, a component returning a "main"
+// element may be rendered into a <div id="app"> element.
+//
+// If there is more than one element found, the first is used. If no element is
+// found, an error of type InvalidTargetError is returned.
+func RenderIntoContainer(selector string, c Component) error {
+	target := document().Call("querySelector", selector)
+	return renderIntoContainer("RenderIntoContainer", target, c)
+}
+
+// document returns the document, panicking if Vecty was compiled natively
+// rather than for a browser (outside of its own tests).
+func document() jsObject {
+	if global() == nil {
+		panic("vecty: only WebAssembly, TinyGo, and testing compilation is supported")
+	}
+	return global().Get("document")
+}
+
 func renderIntoNode(methodName string, node jsObject, c Component) error {
+	return renderRoot(methodName, &root{component: c, target: node})
+}
+
+func renderIntoContainer(methodName string, node jsObject, c Component) error {
+	return renderRoot(methodName, &root{component: c, target: node, container: true})
+}
+
+// renderRoot renders the root's component, and attaches it to the DOM by
+// replacing its target or, for containers, as the only child of its target.
+func renderRoot(methodName string, r *root) error {
+	c, node := r.component, r.target
 	if !node.Truthy() {
 		return InvalidTargetError{method: methodName}
 	}
+	r.batch = &batchRenderer{idx: make(map[Component]int)}
 	// block batch until we're done
-	batch.scheduled = true
+	r.batch.hold()
+	prevRendering := rendering
+	rendering = r
 	nextRender, skip, pendingMounts := renderComponent(c, nil)
+	rendering = prevRendering
 	if skip {
 		panic("vecty: " + methodName + ": Component.SkipRender illegally returned true")
 	}
-	expectTag := toLower(node.Get("nodeName").String())
-	if nextRender.tag != expectTag {
-		return ElementMismatchError{method: methodName, got: nextRender.tag, want: expectTag}
+	if !r.container {
+		expectTag := toLower(node.Get("nodeName").String())
+		if nextRender.tag != expectTag {
+			return ElementMismatchError{method: methodName, got: nextRender.tag, want: expectTag}
+		}
+	}
+	addRoot(r)
+	attach := func() {
+		if r.container {
+			node.Set("textContent", "")
+			node.Call("appendChild", nextRender.node)
+		} else {
+			replaceNode(nextRender.node, node)
+		}
+		r.attached = true
+		mount(pendingMounts...)
+		if m, ok := c.(Mounter); ok {
+			mount(m)
+		}
+		r.batch.release()
 	}
 	doc := global().Get("document")
 	if doc.Get("readyState").String() == "loading" {
@@ -1249,32 +1901,156 @@ func renderIntoNode(methodName string, node jsObject, c Component) error {
 		cb = funcOf(func(this jsObject, args []jsObject) interface{} {
 			cb.Release()
 
-			replaceNode(nextRender.node, node)
-			mount(pendingMounts...)
-			if m, ok := c.(Mounter); ok {
-				mount(m)
+			if !r.unrendered {
+				attach()
 			}
-			requestAnimationFrame(batch.render)
 			return undefined()
 		})
 		doc.Call("addEventListener", "DOMContentLoaded", cb)
 		return nil
 	}
-	replaceNode(nextRender.node, node)
-	mount(pendingMounts...)
-	if m, ok := c.(Mounter); ok {
-		mount(m)
-	}
-	requestAnimationFrame(batch.render)
+	attach()
 	return nil
 }
 
+// root is a tree of components rendered by RenderBody, RenderInto,
+// RenderIntoContainer or one of their variants. Each root has its own batch of
+// pending re-renders, and can be torn down independently of others via
+// Unrender.
+type root struct {
+	// component is the Component rendered.
+	component Component
+	// target is the DOM node replaced by the Component's render, which is
+	// restored by Unrender, or for containers the node the render is appended
+	// to.
+	target jsObject
+	// container is whether the root was rendered into its target, rather
+	// than replacing it.
+	container bool
+	// batch queues the re-renders of the root's components.
+	batch *batchRenderer
+	// attached is whether the Component's render has replaced target, which
+	// only happens once the document has loaded.
+	attached bool
+	// unrendered is whether Unrender was called.
+	unrendered bool
+}
+
+// roots are the roots currently rendered, in the order they were rendered.
+var roots struct {
+	mu   sync.Mutex
+	list []*root
+}
+
+// addRoot adds a rendered root, replacing any root previously rendered with the
+// same Component.
+func addRoot(r *root) {
+	roots.mu.Lock()
+	defer roots.mu.Unlock()
+	for i, prev := range roots.list {
+		if prev.component == r.component {
+			roots.list = append(roots.list[:i], roots.list[i+1:]...)
+			break
+		}
+	}
+	roots.list = append(roots.list, r)
+}
+
+// removeRoot removes a rendered root, and reports whether it was rendered.
+func removeRoot(r *root) bool {
+	roots.mu.Lock()
+	defer roots.mu.Unlock()
+	for i, rendered := range roots.list {
+		if rendered == r {
+			roots.list = append(roots.list[:i], roots.list[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// renderedRoots returns the roots currently rendered.
+func renderedRoots() []*root {
+	roots.mu.Lock()
+	defer roots.mu.Unlock()
+	return append([]*root(nil), roots.list...)
+}
+
+// Unrender tears down the components rendered by RenderBody, RenderInto,
+// RenderIntoContainer or one of their variants, given the Component passed to
+// them: their pending re-renders are discarded, they are unmounted (calling
+// their Unmount methods), their event listeners are released, and the element
+// replaced by the Component's render is put back in place (or, for
+// containers, the Component's render is removed from the container).
+//
+// This allows components to be embedded into a page, and destroyed, repeatedly
+// without leaking memory. Other components rendered by these functions are
+// unaffected.
+//
+// Unrender panics if the Component is not currently rendered by one of these
+// functions, and must not be called from within Render.
+func Unrender(c Component) {
+	if c == nil {
+		panic("vecty: Unrender illegally called with a nil Component argument")
+	}
+	if rendering != nil {
+		panic("vecty: Unrender illegally called during Render")
+	}
+	r := c.Context().root
+	if r == nil || r.component != c || !removeRoot(r) {
+		panic("vecty: Unrender invoked on Component that is not rendered by RenderBody, RenderInto or RenderIntoContainer")
+	}
+	r.unrendered = true
+
+	// The scheduled frame, if any, will find nothing to render.
+	r.batch.mu.Lock()
+	r.batch.drain()
+	r.batch.mu.Unlock()
+
+	h := extractHTML(c)
+	releaseEventListeners(c)
+	unmount(c)
+	switch {
+	case !r.attached:
+	case r.container:
+		r.target.Call("removeChild", h.node)
+	default:
+		replaceNode(r.target, h.node)
+	}
+}
+
+// releaseEventListeners removes and releases the event listeners of the
+// rendered HTML, recursively.
+func releaseEventListeners(e ComponentOrHTML) {
+	switch v := e.(type) {
+	case Component:
+		releaseEventListeners(v.Context().prevRender)
+	case KeyedList:
+		for _, child := range v.html.children {
+			releaseEventListeners(child)
+		}
+	case *HTML:
+		if v == nil || v.node == nil {
+			return
+		}
+		for _, l := range v.eventListeners {
+			v.node.Call("removeEventListener", l.Name, l.wrapper)
+			l.wrapper.Release()
+		}
+		for _, child := range v.children {
+			releaseEventListeners(child)
+		}
+	}
+}
+
 // SetTitle sets the title of the document.
 func SetTitle(title string) {
 	global().Get("document").Set("title", title)
 }
 
-// AddStylesheet adds an external stylesheet to the document.
+// AddStylesheet adds an external stylesheet to the document. It does not apply
+// to components rendered into a shadow root, which must use
+// ShadowRoot.AddStylesheet instead.
 func AddStylesheet(url string) {
 	link := global().Get("document").Call("createElement", "link")
 	link.Set("rel", "stylesheet")
@@ -1282,6 +2058,135 @@ func AddStylesheet(url string) {
 	global().Get("document").Get("head").Call("appendChild", link)
 }
 
+// ShadowRootMode is the mode of a shadow root, which determines whether it is
+// accessible from outside via the shadowRoot property of its host element.
+type ShadowRootMode string
+
+const (
+	// ShadowRootOpen makes the shadow root accessible from outside.
+	ShadowRootOpen ShadowRootMode = "open"
+
+	// ShadowRootClosed makes the shadow root inaccessible from outside.
+	ShadowRootClosed ShadowRootMode = "closed"
+)
+
+// ShadowRoot is the shadow root of an element, into which a component is
+// rendered by RenderIntoShadow. Styles within a shadow root only apply to its
+// content, and styles of the document do not apply to it (other than inherited
+// properties), which isolates components embedded into other pages.
+type ShadowRoot struct {
+	node jsObject
+	// container is the element within the shadow root which components are
+	// rendered into, such that rendering leaves stylesheets in place.
+	container jsObject
+}
+
+// ShadowRootModeError is returned when rendering into the shadow root of an
+// element which already has a shadow root of a different mode.
+type ShadowRootModeError struct {
+	method    string
+	got, want ShadowRootMode
+}
+
+func (e ShadowRootModeError) Error() string {
+	return "vecty: " + e.method + `: expected a shadow root of mode "` + string(e.want) + `", found "` + string(e.got) + `"`
+}
+
+const (
+	// shadowRootKey is the property of a host element holding the shadow root
+	// attached to it by Vecty, as closed shadow roots are not exposed by its
+	// shadowRoot property.
+	shadowRootKey = "__vectyShadowRoot"
+
+	// shadowContainerKey is the property of a shadow root holding the element
+	// which components are rendered into.
+	shadowContainerKey = "__vectyContainer"
+)
+
+// shadowRoot returns the ShadowRoot of the host element, attaching a shadow
+// root with the given mode if it does not have one yet.
+func shadowRoot(methodName string, host jsObject, mode ShadowRootMode) (*ShadowRoot, error) {
+	node, attached := host.Get(shadowRootKey), false
+	if !node.Truthy() {
+		if node = host.Get("shadowRoot"); !node.Truthy() {
+			node, attached = host.Call("attachShadow", map[string]interface{}{"mode": string(mode)}), true
+			host.Set(shadowRootKey, node)
+		}
+	}
+	if !attached {
+		if got := ShadowRootMode(node.Get("mode").String()); got != mode {
+			return nil, ShadowRootModeError{method: methodName, got: got, want: mode}
+		}
+	}
+	container := node.Get(shadowContainerKey)
+	if !container.Truthy() {
+		container = global().Get("document").Call("createElement", "div")
+		container.Get("style").Call("setProperty", "display", "contents")
+		node.Call("appendChild", container)
+		node.Set(shadowContainerKey, container)
+	}
+	return &ShadowRoot{node: node, container: container}, nil
+}
+
+// RenderIntoShadow renders the given component into the shadow root of the
+// existing HTML element found by the CSS selector (e.g. "#widget"), attaching
+// one with the given mode if the element does not have one yet. As with
+// RenderIntoContainer, the Component's Render method may return any element.
+//
+// Stylesheets which apply to the component must be added to the returned
+// ShadowRoot, rather than to the document via AddStylesheet:
+//
+// 	shadow, err := vecty.RenderIntoShadow("#widget", vecty.ShadowRootClosed, &Widget{})
+// 	if err != nil {
+// 		panic(err)
+// 	}
+// 	shadow.AddStylesheet("/widget.css")
+//
+// If there is more than one element found, the first is used. If no element is
+// found, an error of type InvalidTargetError is returned.
+//
+// The component is rendered into an element (styled with display: contents)
+// within the shadow root, which the stylesheets are siblings of. It may be torn
+// down via Unrender, which leaves the shadow root attached along with its
+// stylesheets; rendering into the same element again reuses them. If the
+// element already has a shadow root of a different mode, an error of type
+// ShadowRootModeError is returned.
+func RenderIntoShadow(selector string, mode ShadowRootMode, c Component) (*ShadowRoot, error) {
+	target := document().Call("querySelector", selector)
+	return renderIntoShadow("RenderIntoShadow", target, mode, c)
+}
+
+func renderIntoShadow(methodName string, node jsObject, mode ShadowRootMode, c Component) (*ShadowRoot, error) {
+	if !node.Truthy() {
+		return nil, InvalidTargetError{method: methodName}
+	}
+	shadow, err := shadowRoot(methodName, node, mode)
+	if err != nil {
+		return nil, err
+	}
+	if err := renderIntoContainer(methodName, shadow.container, c); err != nil {
+		return nil, err
+	}
+	return shadow, nil
+}
+
+// AddStylesheet adds an external stylesheet to the shadow root, which only
+// applies to its content.
+func (s *ShadowRoot) AddStylesheet(url string) {
+	link := global().Get("document").Call("createElement", "link")
+	link.Set("rel", "stylesheet")
+	link.Set("href", url)
+	s.node.Call("appendChild", link)
+}
+
+// AddStyle adds the given CSS to the shadow root, which only applies to its
+// content.
+func (s *ShadowRoot) AddStyle(css string) {
+	style := global().Get("document").Call("createElement", "style")
+	style.Set("textContent", css)
+	s.node.Call("appendChild", style)
+}
+
 type jsFunc interface {
 	Release()
 }
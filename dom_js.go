@@ -21,6 +21,15 @@ func (h *HTML) Node() js.Value {
 	return h.node.(wrappedObject).j
 }
 
+// Node returns the JavaScript Element which the Ref refers to, or null if it
+// does not refer to one (see Attached).
+func (r *Ref) Node() js.Value {
+	if r.node == nil {
+		return js.Null()
+	}
+	return r.node.(wrappedObject).j
+}
+
 // RenderIntoNode renders the given component into the existing HTML element by
 // replacing it.
 //
@@ -30,6 +39,27 @@ func RenderIntoNode(node js.Value, c Component) error {
 	return renderIntoNode("RenderIntoNode", wrapObject(node), c)
 }
 
+// RenderIntoContainerNode renders the given component as the content of the
+// existing HTML element, replacing any existing children of the element.
+//
+// Unlike RenderIntoNode, the element itself is kept, so the Component's Render
+// method may return any element.
+func RenderIntoContainerNode(node js.Value, c Component) error {
+	return renderIntoContainer("RenderIntoContainerNode", wrapObject(node), c)
+}
+
+// RenderIntoShadowNode renders the given component into the shadow root of the
+// existing HTML element, attaching one with the given mode if the element does
+// not have one yet.
+func RenderIntoShadowNode(node js.Value, mode ShadowRootMode, c Component) (*ShadowRoot, error) {
+	return renderIntoShadow("RenderIntoShadowNode", wrapObject(node), mode, c)
+}
+
+// Node returns the underlying JavaScript ShadowRoot.
+func (s *ShadowRoot) Node() js.Value {
+	return s.node.(wrappedObject).j
+}
+
 func toLower(s string) string {
 	// We must call the prototype method here to workaround a limitation of
 	// syscall/js in both Go and GopherJS where we cannot call the
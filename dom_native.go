@@ -8,6 +8,9 @@ import "strings"
 // type-checks, lints, auto-completes, and serves documentation under godoc.org
 // as with any other normal Go package that is not under GOOS=js and
 // GOARCH=wasm.
+//
+// Native programs may import Vecty, such that packages building upon it may be
+// tested under native 'go test', but rendering panics as there is no browser.
 
 // SyscallJSValue is an actual syscall/js.Value type under WebAssembly compilation.
 //
@@ -29,6 +32,12 @@ func (h *HTML) Node() SyscallJSValue {
 	return htmlNodeImpl(h)
 }
 
+// Node returns the JavaScript Element which the Ref refers to, or null if it
+// does not refer to one (see Attached).
+func (r *Ref) Node() SyscallJSValue {
+	return r.node
+}
+
 // RenderIntoNode renders the given component into the existing HTML element by
 // replacing it.
 //
@@ -38,6 +47,27 @@ func RenderIntoNode(node SyscallJSValue, c Component) error {
 	return renderIntoNode("RenderIntoNode", node, c)
 }
 
+// RenderIntoContainerNode renders the given component as the content of the
+// existing HTML element, replacing any existing children of the element.
+//
+// Unlike RenderIntoNode, the element itself is kept, so the Component's Render
+// method may return any element.
+func RenderIntoContainerNode(node SyscallJSValue, c Component) error {
+	return renderIntoContainer("RenderIntoContainerNode", node, c)
+}
+
+// RenderIntoShadowNode renders the given component into the shadow root of the
+// existing HTML element, attaching one with the given mode if the element does
+// not have one yet.
+func RenderIntoShadowNode(node SyscallJSValue, mode ShadowRootMode, c Component) (*ShadowRoot, error) {
+	return renderIntoShadow("RenderIntoShadowNode", node, mode, c)
+}
+
+// Node returns the underlying JavaScript ShadowRoot.
+func (s *ShadowRoot) Node() SyscallJSValue {
+	return s.node
+}
+
 func toLower(s string) string {
 	return strings.ToLower(s)
 }
@@ -7,7 +7,8 @@ func init() {
 		return
 	}
 	if global() == nil {
-		panic("vecty: only WebAssembly, TinyGo, and testing compilation is supported")
+		// Compiled natively, in which case rendering panics (see document).
+		return
 	}
 	if global().Get("document").IsUndefined() {
 		panic("vecty: only running inside a browser is supported")
@@ -2,7 +2,11 @@ package vecty
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
 	"testing"
+	"time"
 )
 
 type testCore struct{ Core }
@@ -84,6 +88,116 @@ func TestHTML_Node(t *testing.T) {
 	}
 }
 
+func TestRef(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	var ref Ref
+	if ref.Attached() {
+		t.Fatal("got attached zero Ref")
+	}
+	init := Tag("div", Tag("input", Markup(&ref)), Tag("span"))
+	init.reconcile(nil)
+	input := init.children[0].(*HTML)
+	if !ref.Attached() || !ref.node.Equal(input.node) {
+		t.Fatal("got Ref not referring to the input")
+	}
+	ts.record("(first reconcile done)")
+
+	// Moving the Ref to another element refers to it instead.
+	target := Tag("div", Tag("input"), Tag("span", Markup(&ref)))
+	target.reconcile(init)
+	span := target.children[1].(*HTML)
+	if !ref.Attached() || !ref.node.Equal(span.node) {
+		t.Fatal("got Ref not referring to the span")
+	}
+	ts.record("(second reconcile done)")
+
+	// Removing the element clears the Ref.
+	target2 := Tag("div", Tag("input"))
+	target2.reconcile(target)
+	if ref.Attached() {
+		t.Fatal("got Ref attached after its element was removed")
+	}
+}
+
+// TestEnterLeave tests that EnterLeave hooks are called when the element's
+// DOM node is created and removed, and that its removal is delayed until the
+// leave hook is done.
+func TestEnterLeave(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	var (
+		entered []*HTML
+		done    func()
+	)
+	hooks := EnterLeave{
+		Enter: func(h *HTML) { entered = append(entered, h) },
+		Leave: func(h *HTML, d func()) { done = d },
+	}
+	init := Tag("div", Tag("span", Markup(hooks)))
+	init.reconcile(nil)
+	if len(entered) != 1 || entered[0] != init.children[0] {
+		t.Fatal("got Enter not called once for the span")
+	}
+	ts.record("(first reconcile done)")
+
+	// Re-rendering the element must not enter it again.
+	target := Tag("div", Tag("span", Markup(hooks)))
+	target.reconcile(init)
+	if len(entered) != 1 {
+		t.Fatal("got Enter called for a reused node")
+	}
+	ts.record("(second reconcile done)")
+
+	ts.truthies.mock(`global.Get("document").Call("createElement", "span").Get("parentNode")`, true)
+	target2 := Tag("div")
+	target2.reconcile(target)
+	if done == nil {
+		t.Fatal("got Leave not called for the removed span")
+	}
+	ts.record("(expect span to be removed now)")
+	done()
+	done()
+}
+
+// TestEnterLeave_keyed tests that the EnterLeave hooks of keyed list items are
+// called as the items are added and removed by a re-render.
+func TestEnterLeave_keyed(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	var (
+		entered []string
+		left    = map[string]func(){}
+	)
+	item := func(key string) *HTML {
+		return Tag(key, Markup(Key(key), EnterLeave{
+			Enter: func(h *HTML) { entered = append(entered, key) },
+			Leave: func(h *HTML, done func()) { left[key] = done },
+		}))
+	}
+	init := Tag("div", List{item("a"), item("b")})
+	init.reconcile(nil)
+	if len(entered) != 2 {
+		t.Fatalf("got entered %v, want [a b]", entered)
+	}
+	ts.record("(first reconcile done)")
+
+	target := Tag("div", List{item("a"), item("c")})
+	target.reconcile(init)
+	if len(entered) != 3 || entered[2] != "c" {
+		t.Fatalf("got entered %v, want [a b c]", entered)
+	}
+	if len(left) != 1 || left["b"] == nil {
+		t.Fatal("got Leave not called for the removed item only")
+	}
+	ts.record("(expect b to be removed now)")
+	ts.truthies.mock(`global.Get("document").Call("createElement", "b").Get("parentNode")`, true)
+	left["b"]()
+}
+
 // TestHTML_reconcile_std tests that (*HTML).reconcile against an old HTML instance
 // works as expected (i.e. that it updates nodes correctly).
 func TestHTML_reconcile_std(t *testing.T) {
@@ -335,6 +449,90 @@ func TestHTML_reconcile_std(t *testing.T) {
 	// TODO(pdf): test multi-pass reconcile of persistent component pointer children, ref: https://github.com/hexops/vecty/pull/124
 }
 
+// TestHTML_reconcile_controlled tests that (*HTML).reconcile keeps the value
+// of controlled elements in sync with the DOM, preserving the selection of
+// focused elements and leaving those being composed alone.
+func TestHTML_reconcile_controlled(t *testing.T) {
+	const input = `global.Get("document").Call("createElement", "input")`
+	t.Run("unfocused", func(t *testing.T) {
+		ts := testSuite(t)
+		defer ts.done()
+
+		ts.strings.mock(input+`.Get("value")`, "")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, false)
+		ts.strings.mock(input+`.Get("value")`, "a")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, false)
+
+		init := Tag("input", Markup(Controlled(), Property("value", "a")))
+		init.reconcile(nil)
+		ts.record("(first reconcile done)")
+		target := Tag("input", Markup(Controlled(), Property("value", "b")))
+		target.reconcile(init)
+	})
+	t.Run("focused", func(t *testing.T) {
+		ts := testSuite(t)
+		defer ts.done()
+
+		ts.strings.mock(input+`.Get("value")`, "")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, false)
+		ts.strings.mock(input+`.Get("value")`, "helo")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, true)
+		ts.ints.mock(input+`.Get("selectionStart")`, 2)
+		ts.ints.mock(input+`.Get("selectionEnd")`, 3)
+		ts.ints.mock(input+`.Get("value").Get("length")`, 2)
+
+		init := Tag("input", Markup(Controlled(), Property("value", "hello")))
+		init.reconcile(nil)
+		ts.record("(first reconcile done)")
+		target := Tag("input", Markup(Controlled(), Property("value", "he")))
+		target.reconcile(init)
+	})
+	t.Run("composing", func(t *testing.T) {
+		ts := testSuite(t)
+		defer ts.done()
+
+		ts.strings.mock(input+`.Get("value")`, "")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, false)
+		ts.strings.mock(input+`.Get("value")`, "にほ")
+		ts.strings.mock(input+`.Get("value")`, "にほ")
+		ts.bools.mock(input+`.Call("matches", ":focus")`, false)
+
+		init := Tag("input", Markup(Controlled(), Property("value", "に")))
+		init.reconcile(nil)
+		init.eventListeners[0].Listener(nil) // compositionstart
+		ts.record("(first reconcile done)")
+
+		// Whilst composing, the value is left to the input method editor.
+		target := Tag("input", Markup(Controlled(), Property("value", "に")))
+		target.reconcile(init)
+		target.eventListeners[1].Listener(nil) // compositionend
+		ts.record("(second reconcile done)")
+
+		target2 := Tag("input", Markup(Controlled(), Property("value", "日本")))
+		target2.reconcile(target)
+	})
+	t.Run("select", func(t *testing.T) {
+		ts := testSuite(t)
+		defer ts.done()
+
+		const selectElem = `global.Get("document").Call("createElement", "select")`
+		const option = `global.Get("document").Call("createElement", "option")`
+		ts.strings.mock(selectElem+`.Get("value")`, "")
+		ts.bools.mock(selectElem+`.Call("matches", ":focus")`, false)
+		ts.bools.mock(option+`.Get("selected")`, true)
+		ts.bools.mock(option+`.Get("selected")`, false)
+		ts.strings.mock(selectElem+`.Get("value")`, "")
+		ts.bools.mock(selectElem+`.Call("matches", ":focus")`, false)
+
+		h := Tag("select",
+			Markup(Controlled(), Property("value", "b")),
+			Tag("option", Markup(Attribute("value", "a"), Property("selected", false))),
+			Tag("option", Markup(Attribute("value", "b"), Property("selected", true))),
+		)
+		h.reconcile(nil)
+	})
+}
+
 // TestHTML_reconcile_nil tests that (*HTML).reconcile(nil) works as expected (i.e.
 // that it creates nodes correctly).
 func TestHTML_reconcile_nil(t *testing.T) {
@@ -610,7 +808,6 @@ func TestRerender_identical(t *testing.T) {
 	Rerender(comp)
 
 	// Invoke the render callback.
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.invokeCallbackRequestAnimationFrame(0)
 
 	if renderCalled != 2 {
@@ -699,7 +896,6 @@ func TestRerender_change(t *testing.T) {
 			Rerender(comp)
 
 			// Invoke the render callback.
-			ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 			ts.invokeCallbackRequestAnimationFrame(0)
 
 			if renderCalled != 2 {
@@ -718,6 +914,624 @@ func TestRerender_change(t *testing.T) {
 	}
 }
 
+type updateComponent struct {
+	Core
+	ts *testSuiteT
+	id string
+}
+
+func (c *updateComponent) Render() ComponentOrHTML {
+	c.ts.record("(Render " + c.id + ")")
+	return Tag("body")
+}
+
+func (c *updateComponent) Mount() { c.ts.record("(Mount)") }
+
+func (c *updateComponent) BeforeUpdate(prev Component) {
+	c.ts.record("(BeforeUpdate " + prev.(*updateComponent).id + ")")
+}
+
+func (c *updateComponent) Updated(prev Component) {
+	c.ts.record("(Updated " + prev.(*updateComponent).id + ")")
+}
+
+// TestRerender_update tests that the BeforeUpdater and Updater interfaces are
+// invoked around re-renders, but not the initial render.
+func TestRerender_update(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	comp := &updateComponent{ts: ts, id: "original"}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	comp.id = "modified"
+	Rerender(comp)
+	ts.invokeCallbackRequestAnimationFrame(0)
+}
+
+// updateOnlyComponent implements BeforeUpdater and Updater, but not Mounter.
+type updateOnlyComponent struct {
+	Core
+	ts *testSuiteT
+	id string
+}
+
+func (c *updateOnlyComponent) Render() ComponentOrHTML {
+	c.ts.record("(Render " + c.id + ")")
+	return Tag("body")
+}
+
+func (c *updateOnlyComponent) BeforeUpdate(prev Component) {
+	c.ts.record("(BeforeUpdate " + prev.(*updateOnlyComponent).id + ")")
+}
+
+func (c *updateOnlyComponent) Updated(prev Component) {
+	c.ts.record("(Updated " + prev.(*updateOnlyComponent).id + ")")
+}
+
+// TestRerender_update_notMounter tests that the BeforeUpdater and Updater
+// interfaces are invoked around re-renders of components which do not
+// implement Mounter.
+func TestRerender_update_notMounter(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	comp := &updateOnlyComponent{ts: ts, id: "original"}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	comp.id = "modified"
+	RerenderSync(comp)
+	comp.id = "modified again"
+	RerenderSync(comp)
+}
+
+type pureComponent struct {
+	PureCore
+	Title   string   `vecty:"prop"`
+	Tags    []string `vecty:"prop"`
+	renders int
+}
+
+func (c *pureComponent) Render() ComponentOrHTML {
+	c.renders++
+	return Tag("div")
+}
+
+// TestPureCore tests that components embedding PureCore skip rendering when
+// their properties are unchanged.
+func TestPureCore(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var child *pureComponent
+	title, tags := "a", []string{"x"}
+	parent := &componentFunc{
+		render: func() ComponentOrHTML {
+			// Each render passes an equal, but not identical, slice.
+			return Tag("body", &pureComponent{Title: title, Tags: append([]string(nil), tags...)})
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(parent)
+	child = parent.Context().prevRender.(*HTML).children[0].(*pureComponent)
+	if child.renders != 1 {
+		t.Fatalf("got %d renders, want 1", child.renders)
+	}
+
+	rerender := func() {
+		ts.record("(rerender)")
+		Rerender(parent)
+		ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+		ts.invokeCallbackRequestAnimationFrame(0)
+	}
+
+	// Unchanged properties skip rendering.
+	rerender()
+	if child.renders != 1 {
+		t.Fatalf("got %d renders after unchanged properties, want 1", child.renders)
+	}
+
+	// Changed properties render.
+	tags = []string{"x", "y"}
+	rerender()
+	if child.renders != 2 {
+		t.Fatalf("got %d renders after changed properties, want 2", child.renders)
+	}
+
+	// Rerender of the component itself always renders.
+	Rerender(child)
+	ts.invokeCallbackRequestAnimationFrame(0)
+	if child.renders != 3 {
+		t.Fatalf("got %d renders after Rerender, want 3", child.renders)
+	}
+}
+
+// TestRerender_goroutines tests that Rerender may be called from multiple
+// goroutines concurrently.
+func TestRerender_goroutines(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			Rerender(comp)
+		}()
+	}
+	wg.Wait()
+
+	// Invoke the render callback.
+	ts.invokeCallbackRequestAnimationFrame(0)
+	if renderCalled != 2 {
+		t.Fatalf("got %d renders, want 2", renderCalled)
+	}
+}
+
+// TestRerender_goroutines_rendering tests that Rerender may be called from
+// other goroutines whilst the component is being rendered (run under -race).
+func TestRerender_goroutines_rendering(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	// Frames are scheduled from the goroutines, so must not call into JS.
+	SetScheduler(&ManualScheduler{})
+	defer SetScheduler(AnimationFrameScheduler())
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	comp := &componentFunc{
+		render:     func() ComponentOrHTML { return Tag("body") },
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				Rerender(comp)
+			}
+		}()
+	}
+	for i := 0; i < 10; i++ {
+		RerenderSync(comp)
+	}
+	wg.Wait()
+	ts.record("(rendered concurrently)")
+}
+
+// TestBatchRenderer_requeue tests that components which did not fit into a
+// frame are rendered first in the next one.
+func TestBatchRenderer_requeue(t *testing.T) {
+	a, b, c := &componentFunc{id: "a"}, &componentFunc{id: "b"}, &componentFunc{id: "c"}
+	batch := &batchRenderer{idx: make(map[Component]int), scheduled: true}
+	// c and a were queued whilst rendering.
+	batch.add(c, PriorityNormal)
+	batch.add(a, PriorityNormal)
+	batch.requeue([]Component{a, b}, []Priority{PriorityNormal, PriorityNormal})
+
+	if got, want := batchIDs(t, batch), []string{"a", "b", "c"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+}
+
+// TestBatchRenderer_priority tests that components are queued in order of
+// priority, and then in the order they were queued in.
+func TestBatchRenderer_priority(t *testing.T) {
+	a, b, c, d := &componentFunc{id: "a"}, &componentFunc{id: "b"}, &componentFunc{id: "c"}, &componentFunc{id: "d"}
+	batch := &batchRenderer{idx: make(map[Component]int), scheduled: true}
+	batch.add(a, PriorityNormal)
+	batch.add(b, PriorityBackground)
+	batch.add(c, PriorityUserBlocking)
+	batch.add(d, PriorityNormal)
+	if got, want := batchIDs(t, batch), []string{"c", "a", "d", "b"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	// Queueing again with a higher priority moves the component up, whilst
+	// queueing with a lower priority keeps its priority.
+	batch.add(b, PriorityUserBlocking)
+	batch.add(c, PriorityBackground)
+	if got, want := batchIDs(t, batch), []string{"b", "c", "a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	// Requeued components go ahead of those of the same priority.
+	pending, priorities := batch.drain()
+	batch.add(d, PriorityBackground)
+	batch.add(a, PriorityUserBlocking)
+	batch.requeue(pending[2:], priorities[2:])
+	if got, want := batchIDs(t, batch), []string{"a", "d"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+	if want := []Priority{PriorityUserBlocking, PriorityNormal}; !reflect.DeepEqual(batch.priorities, want) {
+		t.Fatalf("got priorities %v want %v", batch.priorities, want)
+	}
+}
+
+// batchIDs returns the IDs of the components queued in the batch, checking
+// that they are indexed correctly.
+func batchIDs(t *testing.T, batch *batchRenderer) []string {
+	t.Helper()
+	if len(batch.priorities) != len(batch.batch) || len(batch.idx) != len(batch.batch) {
+		t.Fatalf("got %d priorities and %d indexes for %d components", len(batch.priorities), len(batch.idx), len(batch.batch))
+	}
+	var ids []string
+	for i, comp := range batch.batch {
+		ids = append(ids, comp.(*componentFunc).id)
+		if batch.idx[comp] != i {
+			t.Fatalf("got index %d for %s, want %d", batch.idx[comp], comp.(*componentFunc).id, i)
+		}
+	}
+	return ids
+}
+
+// TestFlush tests that Flush renders queued components immediately.
+func TestFlush(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var renderCalled int
+	var comp *componentFunc
+	comp = &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			if renderCalled == 3 {
+				got := recoverStr(Flush)
+				if want := "vecty: Flush illegally called during Render"; got != want {
+					t.Fatalf("got panic %q want %q", got, want)
+				}
+			}
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	Rerender(comp)
+	Flush()
+	ts.record("(expect flushed render)")
+	if renderCalled != 2 {
+		t.Fatalf("got %d renders, want 2", renderCalled)
+	}
+	if len(comp.Context().root.batch.batch) != 0 {
+		t.Fatal("len(batch.batch) != 0")
+	}
+
+	// Flushing during Render panics.
+	Rerender(comp)
+	Flush()
+	if renderCalled != 3 {
+		t.Fatalf("got %d renders, want 3", renderCalled)
+	}
+}
+
+// TestRerenderSync tests that RerenderSync renders the component immediately,
+// leaving other queued components queued.
+func TestRerenderSync(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var parentRenders, childRenders int
+	child := &componentFunc{
+		render: func() ComponentOrHTML {
+			childRenders++
+			return Tag("div")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	parent := &componentFunc{
+		render: func() ComponentOrHTML {
+			parentRenders++
+			return Tag("body", child)
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(parent)
+	ts.record("(expect body to be set now)")
+
+	Rerender(parent)
+	Rerender(child)
+	RerenderSync(child)
+	if parentRenders != 1 || childRenders != 2 {
+		t.Fatalf("got %d parent and %d child renders, want 1 and 2", parentRenders, childRenders)
+	}
+	if len(parent.Context().root.batch.batch) != 1 || parent.Context().root.batch.batch[0] != parent {
+		t.Fatal("got parent no longer queued")
+	}
+	ts.record("(expect parent to be rendered on next frame)")
+	ts.invokeCallbackRequestAnimationFrame(0)
+	if parentRenders != 2 {
+		t.Fatalf("got %d parent renders, want 2", parentRenders)
+	}
+}
+
+// TestSetScheduler tests that frames are scheduled via the configured
+// Scheduler.
+func TestSetScheduler(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	s := &ManualScheduler{}
+	SetScheduler(s)
+	defer SetScheduler(AnimationFrameScheduler())
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+	if s.Run() {
+		t.Fatal("got frame scheduled by RenderBody with nothing to render")
+	}
+
+	Rerender(comp)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	if !s.Run() {
+		t.Fatal("got no frame scheduled by Rerender")
+	}
+	if renderCalled != 2 {
+		t.Fatalf("got %d renders, want 2", renderCalled)
+	}
+	ts.record("(expect rerendered)")
+	if s.Run() {
+		t.Fatal("got frame scheduled with nothing left to render")
+	}
+}
+
+// TestReadRenderStats tests that the render loop only wakes up whilst
+// components are queued.
+func TestReadRenderStats(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	s := &ManualScheduler{}
+	SetScheduler(s)
+	defer SetScheduler(AnimationFrameScheduler())
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	comp := &componentFunc{
+		render:     func() ComponentOrHTML { return Tag("body") },
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	// An idle application never wakes up.
+	for i := 0; i < 3; i++ {
+		s.Run()
+	}
+	if got, want := ReadRenderStats(), (RenderStats{}); got != want {
+		t.Fatalf("got %+v whilst idle, want %+v", got, want)
+	}
+
+	// Each Rerender wakes it up once.
+	Rerender(comp)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	for i := 0; i < 3; i++ {
+		s.Run()
+	}
+	if got, want := ReadRenderStats(), (RenderStats{Frames: 1}); got != want {
+		t.Fatalf("got %+v after Rerender, want %+v", got, want)
+	}
+	ts.record("(expect rerendered)")
+
+	// Flushing leaves nothing for the scheduled frame to render.
+	Rerender(comp)
+	Flush()
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	for i := 0; i < 3; i++ {
+		s.Run()
+	}
+	if got, want := ReadRenderStats(), (RenderStats{Frames: 2, IdleFrames: 1}); got != want {
+		t.Fatalf("got %+v after Flush, want %+v", got, want)
+	}
+}
+
+// syncScheduler is a Scheduler which renders before Schedule returns.
+type syncScheduler struct{}
+
+func (syncScheduler) Schedule(render func(startTime float64)) { render(0) }
+
+// TestSetScheduler_synchronous tests that a Scheduler may render before
+// Schedule returns, without deadlocking.
+func TestSetScheduler_synchronous(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	SetScheduler(syncScheduler{})
+	defer SetScheduler(AnimationFrameScheduler())
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	Rerender(comp)
+	if renderCalled != 2 {
+		t.Fatalf("got %d renders, want 2", renderCalled)
+	}
+}
+
+// TestSetFrameBudget tests that components which do not fit into the frame
+// budget are rendered in the next frame.
+func TestSetFrameBudget(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	s := &ManualScheduler{}
+	SetScheduler(s)
+	defer SetScheduler(AnimationFrameScheduler())
+	SetFrameBudget(FrameBudget{Duration: 10 * time.Millisecond, Headroom: 2})
+	defer SetFrameBudget(DefaultFrameBudget)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var firstRenders, secondRenders int
+	first := &componentFunc{
+		render: func() ComponentOrHTML {
+			firstRenders++
+			return Tag("div")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	second := &componentFunc{
+		render: func() ComponentOrHTML {
+			secondRenders++
+			return Tag("span")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(&componentFunc{
+		render: func() ComponentOrHTML {
+			return Tag("body", first, second)
+		},
+	})
+
+	// Rendering the first component takes 9ms, leaving too little of the 10ms
+	// budget for the second.
+	Rerender(first)
+	Rerender(second)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 9.0)
+	s.Run()
+	if firstRenders != 2 || secondRenders != 1 {
+		t.Fatalf("got %d first and %d second renders, want 2 and 1", firstRenders, secondRenders)
+	}
+	if len(second.Context().root.batch.batch) != 1 || second.Context().root.batch.batch[0] != second {
+		t.Fatal("got second component no longer queued")
+	}
+
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	s.Run()
+	if secondRenders != 2 {
+		t.Fatalf("got %d second renders, want 2", secondRenders)
+	}
+}
+
+// TestRerenderWithPriority tests that user-blocking renders are rendered first
+// and regardless of the frame budget, whilst background renders are deferred.
+func TestRerenderWithPriority(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	s := &ManualScheduler{}
+	SetScheduler(s)
+	defer SetScheduler(AnimationFrameScheduler())
+	SetFrameBudget(FrameBudget{Duration: 10 * time.Millisecond, Headroom: 2})
+	defer SetFrameBudget(DefaultFrameBudget)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	var rendered []string
+	newComponent := func(tag string) *componentFunc {
+		return &componentFunc{
+			render: func() ComponentOrHTML {
+				rendered = append(rendered, tag)
+				return Tag(tag)
+			},
+			skipRender: func(prev Component) bool { return false },
+		}
+	}
+	results, input, button := newComponent("ul"), newComponent("input"), newComponent("button")
+	RenderBody(&componentFunc{
+		render: func() ComponentOrHTML {
+			return Tag("body", results, input, button)
+		},
+	})
+	ts.record("(expect body to be set now)")
+
+	// Rendering the user-blocking components takes 9ms, leaving too little of
+	// the 10ms budget for the background one.
+	rendered = nil
+	RerenderWithPriority(results, PriorityBackground)
+	RerenderWithPriority(input, PriorityUserBlocking)
+	RerenderWithPriority(button, PriorityUserBlocking)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 9.0)
+	s.Run()
+	if want := []string{"input", "button"}; !reflect.DeepEqual(rendered, want) {
+		t.Fatalf("got %v rendered, want %v", rendered, want)
+	}
+	ts.record("(expect background render to be deferred)")
+
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	s.Run()
+	if want := []string{"input", "button", "ul"}; !reflect.DeepEqual(rendered, want) {
+		t.Fatalf("got %v rendered, want %v", rendered, want)
+	}
+}
+
 // TestRerender_Nested tests the behavior of Rerender when there is a
 // nested Component that is exchanged for *HTML.
 func TestRerender_Nested(t *testing.T) {
@@ -803,7 +1617,6 @@ func TestRerender_Nested(t *testing.T) {
 			Rerender(comp)
 
 			// Invoke the render callback.
-			ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 			ts.invokeCallbackRequestAnimationFrame(0)
 
 			if skipRenderCalled != 1 {
@@ -886,7 +1699,6 @@ func TestRerender_persistent(t *testing.T) {
 	Rerender(comp)
 
 	// Invoke the render callback.
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.invokeCallbackRequestAnimationFrame(0)
 
 	if renderCount != 3 {
@@ -950,7 +1762,6 @@ func TestRerender_persistent_direct(t *testing.T) {
 	Rerender(comp)
 
 	// Invoke the render callback.
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.invokeCallbackRequestAnimationFrame(0)
 
 	if renderCount != 3 {
@@ -1047,7 +1858,6 @@ func TestRenderBody_Standard_loaded(t *testing.T) {
 	defer ts.done()
 
 	ts.strings.mock(`global.Get("document").Get("readyState")`, "loaded")
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
 	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
 
@@ -1066,7 +1876,6 @@ func TestRenderBody_Standard_loading(t *testing.T) {
 	defer ts.done()
 
 	ts.strings.mock(`global.Get("document").Get("readyState")`, "loading")
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
 	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
 
@@ -1087,7 +1896,6 @@ func TestRenderBody_Nested(t *testing.T) {
 	defer ts.done()
 
 	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
 	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
 
@@ -1106,6 +1914,205 @@ func TestRenderBody_Nested(t *testing.T) {
 	})
 }
 
+type unmountComponent struct {
+	Core
+	unmounts *int
+}
+
+func (c *unmountComponent) Render() ComponentOrHTML { return Tag("span") }
+func (c *unmountComponent) Unmount()                { *c.unmounts++ }
+
+// TestUnrender tests that several roots are rendered independently, and that
+// Unrender tears down one of them.
+func TestUnrender(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	s := &ManualScheduler{}
+	SetScheduler(s)
+	defer SetScheduler(AnimationFrameScheduler())
+	for _, id := range []string{"#a", "#b"} {
+		ts.truthies.mock(`global.Get("document").Call("querySelector", "`+id+`")`, true)
+		ts.strings.mock(`global.Get("document").Call("querySelector", "`+id+`").Get("nodeName")`, "DIV")
+		ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	}
+
+	var aRenders, bRenders, unmounts int
+	a := &componentFunc{
+		render: func() ComponentOrHTML {
+			aRenders++
+			return Tag("div",
+				Markup(&EventListener{Name: "click", Listener: func(*Event) {}}),
+				&unmountComponent{unmounts: &unmounts},
+			)
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	b := &componentFunc{
+		render: func() ComponentOrHTML {
+			bRenders++
+			return Tag("div")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	if err := RenderInto("#a", a); err != nil {
+		t.Fatal(err)
+	}
+	if err := RenderInto("#b", b); err != nil {
+		t.Fatal(err)
+	}
+	if a.Context().root.batch == b.Context().root.batch {
+		t.Fatal("got roots sharing a batch")
+	}
+	ts.record("(expect both roots to be rendered now)")
+
+	Unrender(a)
+	if unmounts != 1 {
+		t.Fatalf("got %d unmounts, want 1", unmounts)
+	}
+	ts.record("(expect first root to be torn down now)")
+
+	// The torn down root is no longer rendered, whilst the other one is.
+	Rerender(a)
+	Rerender(b)
+	ts.floats.mock(`global.Get("performance").Call("now", )`, 0.0)
+	s.Run()
+	if aRenders != 1 || bRenders != 2 {
+		t.Fatalf("got %d and %d renders, want 1 and 2", aRenders, bRenders)
+	}
+
+	got := recoverStr(func() { Unrender(a) })
+	if want := "vecty: Unrender invoked on Component that is not rendered by RenderBody, RenderInto or RenderIntoContainer"; got != want {
+		t.Fatalf("got panic %q want %q", got, want)
+	}
+}
+
+// TestRenderIntoContainer tests that RenderIntoContainer renders a component of
+// any element type as the content of the target element.
+func TestRenderIntoContainer(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#app")`, true)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			return Tag("main", Text(strconv.Itoa(renderCalled)))
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	if err := RenderIntoContainer("#app", comp); err != nil {
+		t.Fatal(err)
+	}
+	ts.record("(expect component to be appended to container now)")
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	Rerender(comp)
+	ts.invokeCallbackRequestAnimationFrame(0)
+	ts.record("(expect component to be re-rendered now)")
+
+	Unrender(comp)
+	ts.record("(expect component to be removed from container now)")
+
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#missing")`, false)
+	err := RenderIntoContainer("#missing", comp)
+	if want := "vecty: RenderIntoContainer: invalid target element is null or undefined"; err == nil || err.Error() != want {
+		t.Fatalf("got error %v want %q", err, want)
+	}
+}
+
+// TestRenderIntoShadow tests that RenderIntoShadow renders a component into the
+// shadow root of the target element, with stylesheets scoped to it.
+func TestRenderIntoShadow(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	const widget = `global.Get("document").Call("querySelector", "#widget")`
+	ts.truthies.mock(widget, true)
+	ts.truthies.mock(widget+`.Get("__vectyShadowRoot")`, false)
+	ts.truthies.mock(widget+`.Get("shadowRoot")`, false)
+	ts.truthies.mock(widget+`.Call("attachShadow", map[mode:closed]).Get("__vectyContainer")`, false)
+	ts.truthies.mock(`global.Get("document").Call("createElement", "div")`, true)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			return Tag("section")
+		},
+	}
+	shadow, err := RenderIntoShadow("#widget", ShadowRootClosed, comp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ts.record("(expect component to be rendered into the shadow root now)")
+
+	shadow.AddStylesheet("widget.css")
+	shadow.AddStyle("section { color: red; }")
+	ts.record("(expect styles to be added to the shadow root now)")
+
+	Unrender(comp)
+	ts.record("(expect component to be unrendered now)")
+
+	// The closed shadow root is found via the property it was stored in.
+	ts.truthies.mock(widget, true)
+	ts.truthies.mock(widget+`.Get("__vectyShadowRoot")`, true)
+	ts.strings.mock(widget+`.Get("__vectyShadowRoot").Get("mode")`, "closed")
+	ts.truthies.mock(widget+`.Get("__vectyShadowRoot").Get("__vectyContainer")`, true)
+	ts.truthies.mock(widget+`.Get("__vectyShadowRoot").Get("__vectyContainer")`, true) // render target
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	if _, err := RenderIntoShadow("#widget", ShadowRootClosed, &componentFunc{
+		render: func() ComponentOrHTML {
+			return Tag("section")
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	ts.record("(expect component to be rendered into the same shadow root without attaching another)")
+
+	ts.truthies.mock(widget, true)
+	ts.truthies.mock(widget+`.Get("__vectyShadowRoot")`, true)
+	ts.strings.mock(widget+`.Get("__vectyShadowRoot").Get("mode")`, "closed")
+	_, err = RenderIntoShadow("#widget", ShadowRootOpen, comp)
+	if _, ok := err.(ShadowRootModeError); !ok {
+		t.Fatalf("got error %v want ShadowRootModeError", err)
+	}
+
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#missing")`, false)
+	if _, err := RenderIntoShadow("#missing", ShadowRootOpen, comp); err == nil {
+		t.Fatal("got no error for missing target")
+	}
+}
+
+// TestRenderIntoShadow_loading tests that styles added to a shadow root whilst
+// the DOM is loading are not removed once the component is attached.
+func TestRenderIntoShadow_loading(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#widget")`, true)
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#widget").Get("__vectyShadowRoot")`, false)
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#widget").Get("shadowRoot")`, false)
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "#widget").Call("attachShadow", map[mode:open]).Get("__vectyContainer")`, false)
+	ts.truthies.mock(`global.Get("document").Call("createElement", "div")`, true)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "loading")
+
+	shadow, err := RenderIntoShadow("#widget", ShadowRootOpen, &componentFunc{
+		render: func() ComponentOrHTML {
+			return Tag("section")
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	shadow.AddStyle("section { color: red; }")
+
+	ts.record("(invoking DOMContentLoaded event listener)")
+	ts.invokeCallbackDOMContentLoaded()
+}
+
 // TestSetTitle tests that the SetTitle function performs the correct DOM
 // operations.
 func TestSetTitle(t *testing.T) {
@@ -1128,7 +2135,6 @@ func TestKeyedChild_DifferentType(t *testing.T) {
 	ts := testSuite(t)
 	defer ts.done()
 
-	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
 	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
 	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
 	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
@@ -1149,8 +2155,8 @@ func TestKeyedChild_DifferentType(t *testing.T) {
 	RenderBody(comp)
 
 	rerender := func() {
-		Rerender(comp)
 		ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+		Rerender(comp)
 		ts.invokeCallbackRequestAnimationFrame(0)
 	}
 
@@ -1169,3 +2175,33 @@ func TestKeyedChild_DifferentType(t *testing.T) {
 
 	rerender()
 }
+
+type propCopierComponent struct {
+	Core
+	Title  string `vecty:"prop"`
+	copied bool
+}
+
+func (c *propCopierComponent) Render() ComponentOrHTML { return nil }
+
+func (c *propCopierComponent) CopyProps(dst Component) {
+	d := dst.(*propCopierComponent)
+	d.Title = c.Title
+	d.copied = true
+}
+
+// TestCopyProps tests that copyProps uses the PropCopier interface if
+// implemented, and reflection otherwise.
+func TestCopyProps(t *testing.T) {
+	src, dst := &propCopierComponent{Title: "a"}, &propCopierComponent{}
+	copyProps(src, dst)
+	if dst.Title != "a" || !dst.copied {
+		t.Fatalf("got %+v, want Title copied by CopyProps", dst)
+	}
+
+	srcPure, dstPure := &pureComponent{Title: "a", renders: 1}, &pureComponent{}
+	copyProps(srcPure, dstPure)
+	if dstPure.Title != "a" || dstPure.renders != 0 {
+		t.Fatalf("got %+v, want only Title copied", dstPure)
+	}
+}
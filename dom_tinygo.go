@@ -76,6 +76,11 @@ You will need to implement the 'vecty.Copier' interface on your component, e.g.:
 		return &cpy
 	}
 
+Alternatively, generate it for all components in your package by adding the
+following to one of its files and running 'go generate':
+
+	//go:generate go run github.com/hexops/vecty/cmd/vectygen
+
 ## Which component?
 
 Vecty has printed as much information as it can about the component above. Unfortunately, you will need to hunt it down yourself.
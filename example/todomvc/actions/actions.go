@@ -2,6 +2,22 @@ package actions
 
 import "github.com/hexops/vecty/example/todomvc/store/model"
 
+// Action is an action which changes the application state. It is only
+// implemented by the actions of this package, which the store's reducer
+// switches on.
+type Action interface {
+	isAction()
+}
+
+func (*ReplaceItems) isAction()    {}
+func (*AddItem) isAction()         {}
+func (*DestroyItem) isAction()     {}
+func (*SetTitle) isAction()        {}
+func (*SetCompleted) isAction()    {}
+func (*SetAllCompleted) isAction() {}
+func (*ClearCompleted) isAction()  {}
+func (*SetFilter) isAction()       {}
+
 // ReplaceItems is an action that replaces all items with the specified ones.
 type ReplaceItems struct {
 	Items []*model.Item
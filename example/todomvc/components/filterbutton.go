@@ -5,7 +5,6 @@ import (
 	"github.com/hexops/vecty/elem"
 	"github.com/hexops/vecty/event"
 	"github.com/hexops/vecty/example/todomvc/actions"
-	"github.com/hexops/vecty/example/todomvc/dispatcher"
 	"github.com/hexops/vecty/example/todomvc/store"
 	"github.com/hexops/vecty/example/todomvc/store/model"
 	"github.com/hexops/vecty/prop"
@@ -21,7 +20,7 @@ type FilterButton struct {
 }
 
 func (b *FilterButton) onClick(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.SetFilter{
+	store.Dispatch(&actions.SetFilter{
 		Filter: b.Filter,
 	})
 }
@@ -31,7 +30,7 @@ func (b *FilterButton) Render() vecty.ComponentOrHTML {
 	return elem.ListItem(
 		elem.Anchor(
 			vecty.Markup(
-				vecty.MarkupIf(store.Filter == b.Filter, vecty.Class("selected")),
+				vecty.MarkupIf(store.Filter() == b.Filter, vecty.Class("selected")),
 				prop.Href("#"),
 				event.Click(b.onClick).PreventDefault(),
 			),
@@ -5,9 +5,9 @@ import (
 	"github.com/hexops/vecty/elem"
 	"github.com/hexops/vecty/event"
 	"github.com/hexops/vecty/example/todomvc/actions"
-	"github.com/hexops/vecty/example/todomvc/dispatcher"
-	"github.com/hexops/vecty/example/todomvc/store/model"
+	"github.com/hexops/vecty/example/todomvc/store"
 	"github.com/hexops/vecty/prop"
+	vectystore "github.com/hexops/vecty/store"
 	"github.com/hexops/vecty/style"
 )
 
@@ -15,12 +15,12 @@ import (
 // list.
 type ItemView struct {
 	vecty.Core
+	vectystore.Subscriptions
 
-	Index     int         `vecty:"prop"`
-	Item      *model.Item `vecty:"prop"`
+	Index     int `vecty:"prop"`
 	editing   bool
 	editTitle string
-	input     *vecty.HTML
+	input     vecty.Ref
 }
 
 // Key implements the vecty.Keyer interface.
@@ -28,14 +28,22 @@ func (p *ItemView) Key() interface{} {
 	return p.Index
 }
 
+// Mount implements the vecty.Mounter interface.
+func (p *ItemView) Mount() {
+	// Re-render the item whenever it changes.
+	store.Store.Select(p, func(state interface{}) interface{} {
+		return state.(*store.State).Item(p.Index)
+	})
+}
+
 func (p *ItemView) onDestroy(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.DestroyItem{
+	store.Dispatch(&actions.DestroyItem{
 		Index: p.Index,
 	})
 }
 
 func (p *ItemView) onToggleCompleted(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.SetCompleted{
+	store.Dispatch(&actions.SetCompleted{
 		Index:     p.Index,
 		Completed: event.Target.Get("checked").Bool(),
 	})
@@ -43,7 +51,7 @@ func (p *ItemView) onToggleCompleted(event *vecty.Event) {
 
 func (p *ItemView) onStartEdit(event *vecty.Event) {
 	p.editing = true
-	p.editTitle = p.Item.Title
+	p.editTitle = store.Item(p.Index).Title
 	vecty.Rerender(p)
 	p.input.Node().Call("focus")
 }
@@ -56,7 +64,7 @@ func (p *ItemView) onEditInput(event *vecty.Event) {
 func (p *ItemView) onStopEdit(event *vecty.Event) {
 	p.editing = false
 	vecty.Rerender(p)
-	dispatcher.Dispatch(&actions.SetTitle{
+	store.Dispatch(&actions.SetTitle{
 		Index: p.Index,
 		Title: p.editTitle,
 	})
@@ -64,18 +72,15 @@ func (p *ItemView) onStopEdit(event *vecty.Event) {
 
 // Render implements the vecty.Component interface.
 func (p *ItemView) Render() vecty.ComponentOrHTML {
-	p.input = elem.Input(
-		vecty.Markup(
-			vecty.Class("edit"),
-			prop.Value(p.editTitle),
-			event.Input(p.onEditInput),
-		),
-	)
-
+	item := store.Item(p.Index)
+	if item == nil {
+		// Destroyed, and about to be removed by PageView.
+		return nil
+	}
 	return elem.ListItem(
 		vecty.Markup(
 			vecty.ClassMap{
-				"completed": p.Item.Completed,
+				"completed": item.Completed,
 				"editing":   p.editing,
 			},
 		),
@@ -89,7 +94,7 @@ func (p *ItemView) Render() vecty.ComponentOrHTML {
 				vecty.Markup(
 					vecty.Class("toggle"),
 					prop.Type(prop.TypeCheckbox),
-					prop.Checked(p.Item.Completed),
+					prop.Checked(item.Completed),
 					event.Change(p.onToggleCompleted),
 				),
 			),
@@ -97,7 +102,7 @@ func (p *ItemView) Render() vecty.ComponentOrHTML {
 				vecty.Markup(
 					event.DoubleClick(p.onStartEdit),
 				),
-				vecty.Text(p.Item.Title),
+				vecty.Text(item.Title),
 			),
 			elem.Button(
 				vecty.Markup(
@@ -111,7 +116,15 @@ func (p *ItemView) Render() vecty.ComponentOrHTML {
 				style.Margin(style.Px(0)),
 				event.Submit(p.onStopEdit).PreventDefault(),
 			),
-			p.input,
+			elem.Input(
+				vecty.Markup(
+					&p.input,
+					vecty.Class("edit"),
+					vecty.Controlled(),
+					prop.Value(p.editTitle),
+					event.Input(p.onEditInput),
+				),
+			),
 		),
 	)
 }
@@ -7,28 +7,62 @@ import (
 	"github.com/hexops/vecty/elem"
 	"github.com/hexops/vecty/event"
 	"github.com/hexops/vecty/example/todomvc/actions"
-	"github.com/hexops/vecty/example/todomvc/dispatcher"
 	"github.com/hexops/vecty/example/todomvc/store"
 	"github.com/hexops/vecty/example/todomvc/store/model"
 	"github.com/hexops/vecty/prop"
+	vectystore "github.com/hexops/vecty/store"
 	"github.com/hexops/vecty/style"
 )
 
+//go:generate go run github.com/hexops/vecty/cmd/vectygen
+
 // PageView is a vecty.Component which represents the entire page.
 type PageView struct {
 	vecty.Core
+	vectystore.Subscriptions
 
-	Items        []*model.Item `vecty:"prop"`
 	newItemTitle string
 }
 
+// pageState is the slice of the application state which PageView renders.
+// The items themselves are rendered by ItemView, which selects its own item,
+// such that changing an item does not re-render the whole page.
+type pageState struct {
+	filter           model.FilterState
+	visible          []int // indexes of the items shown by the filter
+	total, completed int
+}
+
+// selectPage selects the slice of the given state which PageView renders.
+func selectPage(s *store.State) pageState {
+	page := pageState{filter: s.Filter, total: len(s.Items)}
+	for i, item := range s.Items {
+		if item.Completed {
+			page.completed++
+		}
+		if (s.Filter == model.Active && item.Completed) || (s.Filter == model.Completed && !item.Completed) {
+			continue
+		}
+		page.visible = append(page.visible, i)
+	}
+	return page
+}
+
+// Mount implements the vecty.Mounter interface.
+func (p *PageView) Mount() {
+	// Re-render the page whenever the slice of the state it renders changes.
+	store.Store.Select(p, func(state interface{}) interface{} {
+		return selectPage(state.(*store.State))
+	})
+}
+
 func (p *PageView) onNewItemTitleInput(event *vecty.Event) {
 	p.newItemTitle = event.Target.Get("value").String()
 	vecty.Rerender(p)
 }
 
 func (p *PageView) onAdd(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.AddItem{
+	store.Dispatch(&actions.AddItem{
 		Title: p.newItemTitle,
 	})
 	p.newItemTitle = ""
@@ -36,17 +70,18 @@ func (p *PageView) onAdd(event *vecty.Event) {
 }
 
 func (p *PageView) onClearCompleted(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.ClearCompleted{})
+	store.Dispatch(&actions.ClearCompleted{})
 }
 
 func (p *PageView) onToggleAllCompleted(event *vecty.Event) {
-	dispatcher.Dispatch(&actions.SetAllCompleted{
+	store.Dispatch(&actions.SetAllCompleted{
 		Completed: event.Target.Get("checked").Bool(),
 	})
 }
 
 // Render implements the vecty.Component interface.
 func (p *PageView) Render() vecty.ComponentOrHTML {
+	page := selectPage(store.Current())
 	return elem.Body(
 		elem.Section(
 			vecty.Markup(
@@ -54,9 +89,9 @@ func (p *PageView) Render() vecty.ComponentOrHTML {
 			),
 
 			p.renderHeader(),
-			vecty.If(len(store.Items) > 0,
-				p.renderItemList(),
-				p.renderFooter(),
+			vecty.If(page.total > 0,
+				p.renderItemList(page),
+				p.renderFooter(page),
 			),
 		),
 
@@ -84,6 +119,7 @@ func (p *PageView) renderHeader() *vecty.HTML {
 					vecty.Class("new-todo"),
 					prop.Placeholder("What needs to be done?"),
 					prop.Autofocus(true),
+					vecty.Controlled(),
 					prop.Value(p.newItemTitle),
 					event.Input(p.onNewItemTitleInput),
 				),
@@ -92,8 +128,8 @@ func (p *PageView) renderHeader() *vecty.HTML {
 	)
 }
 
-func (p *PageView) renderFooter() *vecty.HTML {
-	count := store.ActiveItemCount()
+func (p *PageView) renderFooter(page pageState) *vecty.HTML {
+	count := page.total - page.completed
 	itemsLeftText := " items left"
 	if count == 1 {
 		itemsLeftText = " item left"
@@ -126,13 +162,13 @@ func (p *PageView) renderFooter() *vecty.HTML {
 			&FilterButton{Label: "Completed", Filter: model.Completed},
 		),
 
-		vecty.If(store.CompletedItemCount() > 0,
+		vecty.If(page.completed > 0,
 			elem.Button(
 				vecty.Markup(
 					vecty.Class("clear-completed"),
 					event.Click(p.onClearCompleted),
 				),
-				vecty.Text("Clear completed ("+strconv.Itoa(store.CompletedItemCount())+")"),
+				vecty.Text("Clear completed ("+strconv.Itoa(page.completed)+")"),
 			),
 		),
 	)
@@ -168,13 +204,10 @@ func (p *PageView) renderInfo() *vecty.HTML {
 	)
 }
 
-func (p *PageView) renderItemList() *vecty.HTML {
-	var items vecty.List
-	for i, item := range store.Items {
-		if (store.Filter == model.Active && item.Completed) || (store.Filter == model.Completed && !item.Completed) {
-			continue
-		}
-		items = append(items, &ItemView{Index: i, Item: item})
+func (p *PageView) renderItemList(page pageState) *vecty.HTML {
+	items := make(vecty.List, len(page.visible))
+	for i, index := range page.visible {
+		items[i] = &ItemView{Index: index}
 	}
 
 	return elem.Section(
@@ -187,7 +220,7 @@ func (p *PageView) renderItemList() *vecty.HTML {
 				vecty.Class("toggle-all"),
 				prop.ID("toggle-all"),
 				prop.Type(prop.TypeCheckbox),
-				prop.Checked(store.CompletedItemCount() == len(store.Items)),
+				prop.Checked(page.completed == page.total),
 				event.Change(p.onToggleAllCompleted),
 			),
 		),
@@ -0,0 +1,41 @@
+// Code generated by "vectygen"; DO NOT EDIT.
+
+package components
+
+import "github.com/hexops/vecty"
+
+// Copy implements the vecty.Copier interface.
+func (c *FilterButton) Copy() vecty.Component {
+	cpy := *c
+	return &cpy
+}
+
+// CopyProps implements the vecty.PropCopier interface.
+func (c *FilterButton) CopyProps(dst vecty.Component) {
+	d := dst.(*FilterButton)
+	d.Label = c.Label
+	d.Filter = c.Filter
+}
+
+// Copy implements the vecty.Copier interface.
+func (c *ItemView) Copy() vecty.Component {
+	cpy := *c
+	return &cpy
+}
+
+// CopyProps implements the vecty.PropCopier interface.
+func (c *ItemView) CopyProps(dst vecty.Component) {
+	d := dst.(*ItemView)
+	d.Index = c.Index
+}
+
+// Copy implements the vecty.Copier interface.
+func (c *PageView) Copy() vecty.Component {
+	cpy := *c
+	return &cpy
+}
+
+// CopyProps implements the vecty.PropCopier interface.
+func (c *PageView) CopyProps(dst vecty.Component) {
+	_ = dst.(*PageView)
+}
@@ -1,15 +1,12 @@
 package main
 
 import (
-	"encoding/json"
-	"syscall/js"
-
 	"github.com/hexops/vecty"
 	"github.com/hexops/vecty/example/todomvc/actions"
 	"github.com/hexops/vecty/example/todomvc/components"
-	"github.com/hexops/vecty/example/todomvc/dispatcher"
 	"github.com/hexops/vecty/example/todomvc/store"
 	"github.com/hexops/vecty/example/todomvc/store/model"
+	"github.com/hexops/vecty/store/persist"
 )
 
 func main() {
@@ -18,30 +15,25 @@ func main() {
 	vecty.SetTitle("GopherJS • TodoMVC")
 	vecty.AddStylesheet("https://rawgit.com/tastejs/todomvc-common/master/base.css")
 	vecty.AddStylesheet("https://rawgit.com/tastejs/todomvc-app-css/master/index.css")
-	p := &components.PageView{}
-	store.Listeners.Add(p, func() {
-		p.Items = store.Items
-		vecty.Rerender(p)
-	})
-	vecty.RenderBody(p)
+	vecty.RenderBody(&components.PageView{})
 }
 
 func attachLocalStorage() {
-	store.Listeners.Add(nil, func() {
-		data, err := json.Marshal(store.Items)
-		if err != nil {
-			println("failed to store items: " + err.Error())
-		}
-		js.Global().Get("localStorage").Set("items", string(data))
-	})
+	p := &persist.Persister{
+		Storage: persist.LocalStorage(),
+		Key:     "items",
+		Select: func(state interface{}) interface{} {
+			return state.(*store.State).Items
+		},
+	}
 
-	if data := js.Global().Get("localStorage").Get("items"); !data.IsUndefined() {
-		var items []*model.Item
-		if err := json.Unmarshal([]byte(data.String()), &items); err != nil {
-			println("failed to load items: " + err.Error())
-		}
-		dispatcher.Dispatch(&actions.ReplaceItems{
+	var items []*model.Item
+	if ok, err := p.Load(&items); err != nil {
+		println("failed to load items: " + err.Error())
+	} else if ok {
+		store.Dispatch(&actions.ReplaceItems{
 			Items: items,
 		})
 	}
+	p.Attach(store.Store)
 }
@@ -2,84 +2,119 @@ package store
 
 import (
 	"github.com/hexops/vecty/example/todomvc/actions"
-	"github.com/hexops/vecty/example/todomvc/dispatcher"
 	"github.com/hexops/vecty/example/todomvc/store/model"
-	"github.com/hexops/vecty/example/todomvc/store/storeutil"
+	vectystore "github.com/hexops/vecty/store"
 )
 
-var (
+// State represents the state of the application.
+type State struct {
 	// Items represents all of the TODO items in the store.
 	Items []*model.Item
 
 	// Filter represents the active viewing filter for items.
-	Filter = model.All
+	Filter model.FilterState
+}
 
-	// Listeners is the listeners that will be invoked when the store changes.
-	Listeners = storeutil.NewListenerRegistry()
-)
+// Item returns the TODO item at the given index, or nil if there is none.
+func (s *State) Item(index int) *model.Item {
+	if index < 0 || index >= len(s.Items) {
+		return nil
+	}
+	return s.Items[index]
+}
 
-func init() {
-	dispatcher.Register(onAction)
+// Store holds the application state, a *State, which is changed by
+// dispatching an actions.Action.
+var Store = vectystore.New(&State{Filter: model.All}, func(state interface{}, action vectystore.Action) interface{} {
+	return reduce(state.(*State), action.(actions.Action))
+})
+
+// Dispatch dispatches the given action to the store.
+func Dispatch(action actions.Action) {
+	Store.Dispatch(action)
 }
 
-// ActiveItemCount returns the current number of items that are not completed.
-func ActiveItemCount() int {
-	return count(false)
+// Current returns the current application state.
+func Current() *State {
+	return Store.State().(*State)
 }
 
-// CompletedItemCount returns the current number of items that are completed.
-func CompletedItemCount() int {
-	return count(true)
+// Items returns all of the TODO items in the store.
+func Items() []*model.Item {
+	return Current().Items
 }
 
-func count(completed bool) int {
-	count := 0
-	for _, item := range Items {
-		if item.Completed == completed {
-			count++
-		}
-	}
-	return count
+// Item returns the TODO item at the given index, or nil if there is none.
+func Item(index int) *model.Item {
+	return Current().Item(index)
 }
 
-func onAction(action interface{}) {
+// Filter returns the active viewing filter for items.
+func Filter() model.FilterState {
+	return Current().Filter
+}
+
+// reduce applies the action to the state. The state is never modified
+// in-place, so that subscribers can tell what has changed.
+func reduce(state *State, action actions.Action) *State {
+	s := *state
 	switch a := action.(type) {
 	case *actions.ReplaceItems:
-		Items = a.Items
+		s.Items = a.Items
 
 	case *actions.AddItem:
-		Items = append(Items, &model.Item{Title: a.Title, Completed: false})
+		s.Items = append(s.Items[:len(s.Items):len(s.Items)], &model.Item{Title: a.Title, Completed: false})
 
 	case *actions.DestroyItem:
-		copy(Items[a.Index:], Items[a.Index+1:])
-		Items = Items[:len(Items)-1]
+		items := make([]*model.Item, 0, len(s.Items)-1)
+		items = append(items, s.Items[:a.Index]...)
+		s.Items = append(items, s.Items[a.Index+1:]...)
 
 	case *actions.SetTitle:
-		Items[a.Index].Title = a.Title
+		s.Items = updateItems(s.Items, func(i int, item *model.Item) {
+			if i == a.Index {
+				item.Title = a.Title
+			}
+		})
 
 	case *actions.SetCompleted:
-		Items[a.Index].Completed = a.Completed
+		s.Items = updateItems(s.Items, func(i int, item *model.Item) {
+			if i == a.Index {
+				item.Completed = a.Completed
+			}
+		})
 
 	case *actions.SetAllCompleted:
-		for _, item := range Items {
+		s.Items = updateItems(s.Items, func(i int, item *model.Item) {
 			item.Completed = a.Completed
-		}
+		})
 
 	case *actions.ClearCompleted:
 		var activeItems []*model.Item
-		for _, item := range Items {
+		for _, item := range s.Items {
 			if !item.Completed {
 				activeItems = append(activeItems, item)
 			}
 		}
-		Items = activeItems
+		s.Items = activeItems
 
 	case *actions.SetFilter:
-		Filter = a.Filter
+		s.Filter = a.Filter
 
 	default:
-		return // don't fire listeners
+		return state // don't notify subscribers
 	}
+	return &s
+}
 
-	Listeners.Fire()
+// updateItems returns a copy of items, with update applied to a copy of each
+// item.
+func updateItems(items []*model.Item, update func(i int, item *model.Item)) []*model.Item {
+	updated := make([]*model.Item, len(items))
+	for i, item := range items {
+		cpy := *item
+		update(i, &cpy)
+		updated[i] = &cpy
+	}
+	return updated
 }
@@ -0,0 +1,591 @@
+// Package form binds form controls to the fields of a Go struct, and
+// validates them.
+//
+// Fields are bound by name, which is the field's name or the name given by
+// its `form` struct tag (`form:"-"` leaves a field unbound). Validators are
+// declared by the `validate` struct tag, as a comma-separated list of
+// "required", "min=N" and "max=N", and the `pattern` struct tag, a regular
+// expression:
+//
+//	type SignUp struct {
+//		Email    string `form:"email" validate:"required" pattern:"^[^@]+@[^@]+$"`
+//		Password string `form:"password" validate:"required,min=8"`
+//		Age      int    `form:"age" validate:"min=18"`
+//		Terms    bool   `form:"terms" validate:"required"`
+//	}
+//
+// For strings, min and max bound the length; for numbers, the value.
+// Additional validators, including custom functions, may be added via
+// Form.Validate.
+//
+// A Form renders the controls, keeps the struct up to date as the user edits
+// them, and re-renders its component whenever that happens:
+//
+//	type SignUpView struct {
+//		vecty.Core
+//		signUp SignUp
+//		form   *form.Form
+//	}
+//
+//	func (v *SignUpView) Render() vecty.ComponentOrHTML {
+//		if v.form == nil {
+//			v.form = form.New(v, &v.signUp)
+//		}
+//		return elem.Form(
+//			vecty.Markup(v.form.OnSubmit(v.submit)),
+//			v.form.Input("email"), v.form.Error("email"),
+//			v.form.Input("password", prop.Type(prop.TypePassword)), v.form.Error("password"),
+//			...
+//		)
+//	}
+package form
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+	"github.com/hexops/vecty/event"
+	"github.com/hexops/vecty/prop"
+)
+
+// Validator validates the value of a field, returning an error describing
+// why it is invalid, or nil.
+type Validator func(value interface{}) error
+
+// Form binds form controls to the fields of a struct.
+type Form struct {
+	component vecty.Component
+	value     reflect.Value
+	fields    map[string]*field
+	names     []string
+	submitted bool
+}
+
+// field is a struct field bound to a form control.
+type field struct {
+	index      int
+	validators []Validator
+	// raw is the value of the control, which may not be representable by the
+	// field (e.g. "abc" for an int field).
+	raw      string
+	initial  string
+	parseErr error
+	errs     []string
+	dirty    bool
+	touched  bool
+}
+
+// New returns a Form bound to the fields of the struct pointed to by v, which
+// re-renders the given component (via vecty.Rerender) whenever a bound field
+// changes.
+//
+// Supported field types are strings, bools, and integer and floating-point
+// numbers. New panics if v is not a pointer to a struct, or if a struct tag
+// is invalid.
+func New(c vecty.Component, v interface{}) *Form {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Struct {
+		panic("form: New called with non-pointer-to-struct " + rv.Type().String())
+	}
+	f := &Form{
+		component: c,
+		value:     rv.Elem(),
+		fields:    make(map[string]*field),
+	}
+	t := f.value.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		name := sf.Tag.Get("form")
+		if name == "-" || sf.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if !supported(sf.Type.Kind()) {
+			panic(fmt.Sprintf("form: unsupported type %s of field %s", sf.Type, sf.Name))
+		}
+		fd := &field{index: i}
+		fd.validators = tagValidators(sf)
+		fd.raw = format(f.value.Field(i))
+		fd.initial = fd.raw
+		f.fields[name] = fd
+		f.names = append(f.names, name)
+	}
+	return f
+}
+
+// field returns the named field, panicking if it does not exist.
+func (f *Form) field(name string) *field {
+	fd, ok := f.fields[name]
+	if !ok {
+		panic(fmt.Sprintf("form: no field named %q", name))
+	}
+	return fd
+}
+
+// Validate adds validators to the named field, in addition to those declared
+// by its struct tags.
+func (f *Form) Validate(name string, validators ...Validator) {
+	fd := f.field(name)
+	fd.validators = append(fd.validators, validators...)
+}
+
+// Set sets the named field from the string value of its form control, and
+// validates it. Form controls rendered by the Form call it when edited; it
+// is useful for custom controls.
+//
+// If the value cannot be represented by the field (e.g. "abc" for an int
+// field), the field is left unmodified and reported as invalid.
+func (f *Form) Set(name, value string) {
+	fd := f.field(name)
+	fd.raw = value
+	fd.dirty = value != fd.initial
+	fd.parseErr = parse(f.value.Field(fd.index), value)
+	f.validate(fd)
+}
+
+// Touch marks the named field as touched, i.e. visited by the user. Form
+// controls rendered by the Form call it when they lose focus.
+func (f *Form) Touch(name string) {
+	fd := f.field(name)
+	fd.touched = true
+	f.validate(fd)
+}
+
+// validate runs the validators of the field, storing the resulting errors.
+func (f *Form) validate(fd *field) {
+	fd.errs = nil
+	if fd.parseErr != nil {
+		fd.errs = append(fd.errs, fd.parseErr.Error())
+		return
+	}
+	value := f.value.Field(fd.index).Interface()
+	for _, v := range fd.validators {
+		if err := v(value); err != nil {
+			fd.errs = append(fd.errs, err.Error())
+		}
+	}
+}
+
+// ValidateAll validates all fields, and reports whether they are valid.
+// Afterwards, errors are displayed for all invalid fields, whether touched or
+// not.
+func (f *Form) ValidateAll() bool {
+	f.submitted = true
+	valid := true
+	for _, name := range f.names {
+		fd := f.fields[name]
+		f.validate(fd)
+		if len(fd.errs) > 0 {
+			valid = false
+		}
+	}
+	return valid
+}
+
+// Valid reports whether all fields are valid, without displaying errors.
+func (f *Form) Valid() bool {
+	for _, name := range f.names {
+		fd := f.fields[name]
+		f.validate(fd)
+		if len(fd.errs) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Errors returns the errors of the named field, as of the last time it was
+// validated.
+func (f *Form) Errors(name string) []string {
+	return f.field(name).errs
+}
+
+// Dirty reports whether the named field differs from its initial value.
+func (f *Form) Dirty(name string) bool {
+	return f.field(name).dirty
+}
+
+// Touched reports whether the named field has been visited by the user.
+func (f *Form) Touched(name string) bool {
+	return f.field(name).touched
+}
+
+// IsDirty reports whether any field differs from its initial value.
+func (f *Form) IsDirty() bool {
+	for _, fd := range f.fields {
+		if fd.dirty {
+			return true
+		}
+	}
+	return false
+}
+
+// Reset forgets the dirty, touched and validation state of all fields, and
+// considers their current values to be their initial ones.
+func (f *Form) Reset() {
+	f.submitted = false
+	for _, fd := range f.fields {
+		raw := format(f.value.Field(fd.index))
+		*fd = field{index: fd.index, validators: fd.validators, raw: raw, initial: raw}
+	}
+}
+
+// showErrors reports whether errors should be displayed for the field.
+func (f *Form) showErrors(fd *field) bool {
+	return (fd.touched || f.submitted) && len(fd.errs) > 0
+}
+
+// onInput returns an event listener which sets the named field to the value
+// of the event target.
+func (f *Form) onInput(name string) func(*vecty.Event) {
+	return func(e *vecty.Event) {
+		f.Set(name, e.Target.Get("value").String())
+		vecty.Rerender(f.component)
+	}
+}
+
+// onBlur returns an event listener which marks the named field as touched.
+func (f *Form) onBlur(name string) func(*vecty.Event) {
+	return func(e *vecty.Event) {
+		if f.field(name).touched {
+			return
+		}
+		f.Touch(name)
+		vecty.Rerender(f.component)
+	}
+}
+
+// Input renders an input element bound to the named field, with the given
+// additional markup applied. Bool fields are rendered as checkboxes (see
+// Checkbox), and number fields as number inputs.
+func (f *Form) Input(name string, markup ...vecty.Applyer) *vecty.HTML {
+	fd := f.field(name)
+	kind := f.value.Field(fd.index).Kind()
+	if kind == reflect.Bool {
+		return f.Checkbox(name, markup...)
+	}
+	inputType := prop.TypeText
+	if kind != reflect.String {
+		inputType = prop.TypeNumber
+	}
+	return elem.Input(
+		vecty.Markup(
+			vecty.Controlled(),
+			prop.Type(inputType),
+			prop.Name(name),
+			prop.Value(fd.raw),
+			vecty.MarkupIf(f.showErrors(fd), vecty.Attribute("aria-invalid", "true")),
+			event.Input(f.onInput(name)),
+			event.Blur(f.onBlur(name)),
+		),
+		vecty.Markup(markup...),
+	)
+}
+
+// TextArea renders a textarea element bound to the named string field, with
+// the given additional markup applied.
+func (f *Form) TextArea(name string, markup ...vecty.Applyer) *vecty.HTML {
+	fd := f.field(name)
+	return elem.TextArea(
+		vecty.Markup(
+			vecty.Controlled(),
+			prop.Name(name),
+			prop.Value(fd.raw),
+			vecty.MarkupIf(f.showErrors(fd), vecty.Attribute("aria-invalid", "true")),
+			event.Input(f.onInput(name)),
+			event.Blur(f.onBlur(name)),
+		),
+		vecty.Markup(markup...),
+	)
+}
+
+// Checkbox renders a checkbox input element bound to the named bool field,
+// with the given additional markup applied.
+func (f *Form) Checkbox(name string, markup ...vecty.Applyer) *vecty.HTML {
+	fd := f.field(name)
+	return elem.Input(
+		vecty.Markup(
+			vecty.Controlled(),
+			prop.Type(prop.TypeCheckbox),
+			prop.Name(name),
+			prop.Checked(fd.raw == "true"),
+			vecty.MarkupIf(f.showErrors(fd), vecty.Attribute("aria-invalid", "true")),
+			event.Change(func(e *vecty.Event) {
+				f.Set(name, strconv.FormatBool(e.Target.Get("checked").Bool()))
+				vecty.Rerender(f.component)
+			}),
+			event.Blur(f.onBlur(name)),
+		),
+		vecty.Markup(markup...),
+	)
+}
+
+// Option is an option of a select element.
+type Option struct {
+	// Value is the value the field is set to when the option is selected.
+	Value string
+
+	// Label is the text displayed for the option. If empty, Value is
+	// displayed.
+	Label string
+}
+
+// Select renders a select element with the given options, bound to the named
+// field, with the given additional markup applied.
+func (f *Form) Select(name string, options []Option, markup ...vecty.Applyer) *vecty.HTML {
+	fd := f.field(name)
+	opts := make(vecty.List, len(options))
+	for i, o := range options {
+		label := o.Label
+		if label == "" {
+			label = o.Value
+		}
+		opts[i] = elem.Option(
+			vecty.Markup(prop.Value(o.Value)),
+			vecty.Text(label),
+		)
+	}
+	return elem.Select(
+		vecty.Markup(
+			vecty.Controlled(),
+			prop.Name(name),
+			prop.Value(fd.raw),
+			vecty.MarkupIf(f.showErrors(fd), vecty.Attribute("aria-invalid", "true")),
+			event.Change(f.onInput(name)),
+			event.Blur(f.onBlur(name)),
+		),
+		vecty.Markup(markup...),
+		opts,
+	)
+}
+
+// Error renders the errors of the named field, if it is invalid and has been
+// touched (or ValidateAll has been called), as a span element with the class
+// "form-error". Otherwise, it renders nothing.
+func (f *Form) Error(name string) vecty.MarkupOrChild {
+	fd := f.field(name)
+	return vecty.If(f.showErrors(fd),
+		elem.Span(
+			vecty.Markup(vecty.Class("form-error")),
+			vecty.Text(strings.Join(fd.errs, "; ")),
+		),
+	)
+}
+
+// OnSubmit returns an event listener for the submit event of a form element,
+// which validates all fields (see ValidateAll) and then invokes fn if they are
+// valid. The form's component is re-rendered, to display any errors.
+func (f *Form) OnSubmit(fn func()) *vecty.EventListener {
+	return event.Submit(func(e *vecty.Event) {
+		if f.ValidateAll() {
+			fn()
+		}
+		vecty.Rerender(f.component)
+	}).PreventDefault()
+}
+
+// Required returns a Validator which reports zero values (e.g. empty strings,
+// or unchecked checkboxes) as invalid.
+func Required() Validator {
+	return func(value interface{}) error {
+		if reflect.ValueOf(value).IsZero() {
+			return fmt.Errorf("is required")
+		}
+		return nil
+	}
+}
+
+// MinLength returns a Validator which reports strings shorter than n
+// characters as invalid. Empty strings are left to Required.
+func MinLength(n int) Validator {
+	return func(value interface{}) error {
+		s, _ := value.(string)
+		if s != "" && len([]rune(s)) < n {
+			return fmt.Errorf("must be at least %d characters long", n)
+		}
+		return nil
+	}
+}
+
+// MaxLength returns a Validator which reports strings longer than n
+// characters as invalid.
+func MaxLength(n int) Validator {
+	return func(value interface{}) error {
+		s, _ := value.(string)
+		if len([]rune(s)) > n {
+			return fmt.Errorf("must be at most %d characters long", n)
+		}
+		return nil
+	}
+}
+
+// Min returns a Validator which reports numbers less than min as invalid.
+func Min(min float64) Validator {
+	return func(value interface{}) error {
+		if n, ok := toFloat(value); ok && n < min {
+			return fmt.Errorf("must be at least %v", min)
+		}
+		return nil
+	}
+}
+
+// Max returns a Validator which reports numbers greater than max as invalid.
+func Max(max float64) Validator {
+	return func(value interface{}) error {
+		if n, ok := toFloat(value); ok && n > max {
+			return fmt.Errorf("must be at most %v", max)
+		}
+		return nil
+	}
+}
+
+// Pattern returns a Validator which reports strings not matching re as
+// invalid, with the given message. Empty strings are left to Required.
+func Pattern(re *regexp.Regexp, message string) Validator {
+	return func(value interface{}) error {
+		s, _ := value.(string)
+		if s != "" && !re.MatchString(s) {
+			return fmt.Errorf("%s", message)
+		}
+		return nil
+	}
+}
+
+// tagValidators returns the validators declared by the struct tags of the
+// given field.
+func tagValidators(sf reflect.StructField) []Validator {
+	var validators []Validator
+	isString := sf.Type.Kind() == reflect.String
+	if tag := sf.Tag.Get("validate"); tag != "" {
+		for _, rule := range strings.Split(tag, ",") {
+			key, arg := rule, ""
+			if i := strings.Index(rule, "="); i >= 0 {
+				key, arg = rule[:i], rule[i+1:]
+			}
+			var n float64
+			if key == "min" || key == "max" {
+				var err error
+				n, err = strconv.ParseFloat(arg, 64)
+				if err != nil {
+					panic(fmt.Sprintf("form: invalid %s argument %q of field %s", key, arg, sf.Name))
+				}
+			}
+			switch {
+			case key == "required":
+				validators = append(validators, Required())
+			case key == "min" && isString:
+				validators = append(validators, MinLength(int(n)))
+			case key == "max" && isString:
+				validators = append(validators, MaxLength(int(n)))
+			case key == "min":
+				validators = append(validators, Min(n))
+			case key == "max":
+				validators = append(validators, Max(n))
+			default:
+				panic(fmt.Sprintf("form: unknown validator %q of field %s", rule, sf.Name))
+			}
+		}
+	}
+	if pattern := sf.Tag.Get("pattern"); pattern != "" {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			panic(fmt.Sprintf("form: invalid pattern of field %s: %v", sf.Name, err))
+		}
+		validators = append(validators, Pattern(re, "must match "+pattern))
+	}
+	return validators
+}
+
+// supported reports whether fields of the given kind may be bound.
+func supported(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// format returns the string representation of a field's value, as used by
+// form controls.
+func format(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String()
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool())
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// parse sets the field's value from the string representation of a form
+// control.
+func parse(v reflect.Value, s string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return fmt.Errorf("must be true or false")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if s == "" {
+			v.SetInt(0)
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a whole number")
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if s == "" {
+			v.SetUint(0)
+			return nil
+		}
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a positive whole number")
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		if s == "" {
+			v.SetFloat(0)
+			return nil
+		}
+		n, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("must be a number")
+		}
+		v.SetFloat(n)
+	}
+	return nil
+}
+
+// toFloat converts numbers to float64.
+func toFloat(value interface{}) (float64, bool) {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
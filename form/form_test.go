@@ -0,0 +1,179 @@
+package form
+
+import (
+	"errors"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hexops/vecty"
+)
+
+type signUp struct {
+	Email    string `form:"email" validate:"required" pattern:"^[^@]+@[^@]+$"`
+	Password string `form:"password" validate:"required,min=8,max=16"`
+	Age      int    `form:"age" validate:"min=18,max=130"`
+	Terms    bool   `form:"terms" validate:"required"`
+	Comment  string
+	Ignored  string `form:"-"`
+}
+
+type signUpView struct {
+	vecty.Core
+}
+
+func (*signUpView) Render() vecty.ComponentOrHTML { return nil }
+
+func TestForm_Set(t *testing.T) {
+	v := signUp{Age: 20}
+	f := New(&signUpView{}, &v)
+
+	f.Set("email", "gopher@example.com")
+	f.Set("age", "42")
+	f.Set("terms", "true")
+	f.Set("Comment", "hi")
+	want := signUp{Email: "gopher@example.com", Age: 42, Terms: true, Comment: "hi"}
+	if v != want {
+		t.Fatalf("got %+v want %+v", v, want)
+	}
+
+	f.Set("age", "abc")
+	if v.Age != 42 {
+		t.Fatalf("got age %d after invalid input, want unmodified 42", v.Age)
+	}
+	if got, want := f.Errors("age"), []string{"must be a whole number"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got errors %q want %q", got, want)
+	}
+
+	if _, ok := f.fields["Ignored"]; ok {
+		t.Fatal("got field bound despite form:\"-\" tag")
+	}
+}
+
+func TestForm_validate(t *testing.T) {
+	cases := []struct {
+		name, value string
+		want        []string
+	}{
+		{name: "email", value: "", want: []string{"is required"}},
+		{name: "email", value: "gopher", want: []string{"must match ^[^@]+@[^@]+$"}},
+		{name: "email", value: "gopher@example.com"},
+		{name: "password", value: "short", want: []string{"must be at least 8 characters long"}},
+		{name: "password", value: "muchtoolongpassword", want: []string{"must be at most 16 characters long"}},
+		{name: "password", value: "justright"},
+		{name: "age", value: "17", want: []string{"must be at least 18"}},
+		{name: "age", value: "131", want: []string{"must be at most 130"}},
+		{name: "age", value: "18"},
+		{name: "terms", value: "false", want: []string{"is required"}},
+		{name: "terms", value: "true"},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name+"="+tst.value, func(t *testing.T) {
+			f := New(&signUpView{}, &signUp{})
+			f.Set(tst.name, tst.value)
+			if got := f.Errors(tst.name); !reflect.DeepEqual(got, tst.want) {
+				t.Fatalf("got errors %q want %q", got, tst.want)
+			}
+		})
+	}
+}
+
+func TestForm_Validate(t *testing.T) {
+	v := signUp{}
+	f := New(&signUpView{}, &v)
+	f.Validate("Comment", MaxLength(3), func(value interface{}) error {
+		if value.(string) == "no" {
+			return errors.New("must not be no")
+		}
+		return nil
+	})
+	f.Validate("email", Pattern(regexp.MustCompile(`\.org$`), "must be a .org address"))
+
+	f.Set("Comment", "no")
+	if got, want := f.Errors("Comment"), []string{"must not be no"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got errors %q want %q", got, want)
+	}
+	f.Set("email", "gopher@example.com")
+	if got, want := f.Errors("email"), []string{"must be a .org address"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got errors %q want %q", got, want)
+	}
+}
+
+func TestForm_state(t *testing.T) {
+	v := signUp{Email: "gopher@example.com"}
+	f := New(&signUpView{}, &v)
+	if f.IsDirty() || f.Dirty("email") || f.Touched("email") {
+		t.Fatal("got dirty or touched form, want pristine")
+	}
+
+	// Errors are not displayed for untouched fields.
+	f.Set("email", "")
+	if !f.Dirty("email") || !f.IsDirty() {
+		t.Fatal("got pristine email, want dirty")
+	}
+	if f.showErrors(f.fields["email"]) {
+		t.Fatal("got errors displayed for untouched field")
+	}
+	f.Touch("email")
+	if !f.Touched("email") || !f.showErrors(f.fields["email"]) {
+		t.Fatal("got errors not displayed for touched field")
+	}
+
+	// Changing a field back to its initial value makes it pristine again.
+	f.Set("email", "gopher@example.com")
+	if f.Dirty("email") {
+		t.Fatal("got dirty email, want pristine")
+	}
+
+	// ValidateAll displays errors for all fields.
+	if f.ValidateAll() {
+		t.Fatal("got valid form, want invalid")
+	}
+	if !f.showErrors(f.fields["password"]) {
+		t.Fatal("got errors not displayed for untouched field after ValidateAll")
+	}
+
+	f.Set("password", "justright")
+	f.Set("age", "18")
+	f.Set("terms", "true")
+	if !f.Valid() {
+		t.Fatalf("got invalid form, want valid")
+	}
+
+	f.Reset()
+	if f.IsDirty() || f.Touched("email") || f.submitted {
+		t.Fatal("got dirty, touched or submitted form after Reset")
+	}
+	if f.Dirty("password") {
+		t.Fatal("got dirty password after Reset, want current value to be initial")
+	}
+}
+
+func TestNew_panics(t *testing.T) {
+	cases := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "non_pointer", v: signUp{}, want: "form: New called with non-pointer-to-struct form.signUp"},
+		{name: "unsupported_type", v: &struct{ A []string }{}, want: "form: unsupported type []string of field A"},
+		{name: "unknown_validator", v: &struct {
+			A string `validate:"requird"`
+		}{}, want: `form: unknown validator "requird" of field A`},
+		{name: "invalid_min", v: &struct {
+			A string `validate:"min=x"`
+		}{}, want: `form: invalid min argument "x" of field A`},
+	}
+	for _, tst := range cases {
+		t.Run(tst.name, func(t *testing.T) {
+			var got interface{}
+			func() {
+				defer func() { got = recover() }()
+				New(&signUpView{}, tst.v)
+			}()
+			if got != tst.want {
+				t.Fatalf("got panic %v want %q", got, tst.want)
+			}
+		})
+	}
+}
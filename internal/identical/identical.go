@@ -0,0 +1,14 @@
+// Package identical compares values of any type, including those which are
+// not comparable.
+package identical
+
+// Values reports whether a and b are identical as per the == operator. If
+// they are of an uncomparable type, it reports false.
+func Values(a, b interface{}) (same bool) {
+	defer func() {
+		if recover() != nil {
+			same = false
+		}
+	}()
+	return a == b
+}
@@ -0,0 +1,26 @@
+package identical
+
+import "testing"
+
+func TestValues(t *testing.T) {
+	type point struct{ x, y int }
+	s := []int{1}
+	tests := []struct {
+		a, b interface{}
+		want bool
+	}{
+		{1, 1, true},
+		{1, 2, false},
+		{1, 1.0, false},
+		{"a", "a", true},
+		{nil, nil, true},
+		{point{1, 2}, point{1, 2}, true},
+		{s, s, false},
+		{map[string]int{}, nil, false},
+	}
+	for _, tst := range tests {
+		if got := Values(tst.a, tst.b); got != tst.want {
+			t.Errorf("Values(%#v, %#v) = %v want %v", tst.a, tst.b, got, tst.want)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+// Package lazy splits an application into multiple WebAssembly modules, such
+// that code which is rarely used (e.g. admin screens) is only downloaded when
+// it is first rendered, rather than delaying the initial load.
+//
+// Each additional module is a separate main package, which exports its
+// components by name and then blocks forever:
+//
+// 	package main
+//
+// 	func main() {
+// 		lazy.Export("admin", func() vecty.Component {
+// 			return &AdminScreen{}
+// 		})
+// 		select {}
+// 	}
+//
+// It is compiled like the main module:
+//
+// 	GOOS=js GOARCH=wasm go build -o admin.wasm ./cmd/admin
+//
+// The main module then renders the exported component using a Lazy component,
+// which loads the module on demand:
+//
+// 	&lazy.Lazy{
+// 		Module:      "admin.wasm",
+// 		Name:        "admin",
+// 		Placeholder: func() vecty.ComponentOrHTML {
+// 			return elem.Div(vecty.Text("Loading..."))
+// 		},
+// 	}
+//
+// Every module is a separate Go program, with its own copy of the runtime and
+// of Vecty, which is why modules must be large enough for splitting them to be
+// worthwhile. Modules share no Go state, so a lazily loaded component cannot
+// be given props: it must obtain its state by other means, e.g. the URL or the
+// browser's storage. Its render loop is also separate, so it is rendered into
+// its element independently of the component which contains it.
+//
+// The wasm_exec.js support script, which the main module is loaded with, is
+// also used to run additional modules.
+package lazy
+
+import (
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+)
+
+// mountFunc renders an exported component into the given container element,
+// returning a function which unrenders it.
+type mountFunc func(container *vecty.Ref) (unmount func())
+
+// Lazy is a component which renders a component exported by another module,
+// loading that module when first mounted. Modules are loaded once, however
+// many Lazy components render components they export.
+//
+// The exported component is rendered as the content of a div element, to which
+// Markup is applied.
+type Lazy struct {
+	vecty.Core
+
+	// Module is the URL of the module.
+	Module string `vecty:"prop"`
+
+	// Name is the name the component is exported with by the module. If the
+	// module never exports it, the placeholder is rendered indefinitely.
+	Name string `vecty:"prop"`
+
+	// Placeholder renders a placeholder until the module is loaded. If nil,
+	// nothing is rendered.
+	Placeholder func() vecty.ComponentOrHTML `vecty:"prop"`
+
+	// Error renders the error which occurred if the module could not be loaded.
+	// If nil, the placeholder continues to be rendered.
+	Error func(err error) vecty.ComponentOrHTML `vecty:"prop"`
+
+	// Markup is applied to the container element.
+	Markup []vecty.Applyer `vecty:"prop"`
+
+	container vecty.Ref
+	mount     mountFunc
+	err       error
+	unmount   func()
+
+	// loadModule and rerender are loadModule and vecty.Rerender, unless
+	// replaced by tests.
+	loadModule func(module, name string, done func(mount mountFunc, err error))
+	rerender   func(c vecty.Component)
+}
+
+// Render implements the vecty.Component interface.
+func (l *Lazy) Render() vecty.ComponentOrHTML {
+	return elem.Div(
+		vecty.Markup(&l.container, vecty.Markup(l.Markup...)),
+		l.content(),
+	)
+}
+
+// content returns the content of the container. Once loaded it is empty, for
+// the exported component to be rendered into.
+func (l *Lazy) content() vecty.ComponentOrHTML {
+	switch {
+	case l.mount != nil:
+		return nil
+	case l.err != nil && l.Error != nil:
+		return l.Error(l.err)
+	case l.Placeholder != nil:
+		return l.Placeholder()
+	}
+	return nil
+}
+
+// Mount implements the vecty.Mounter interface.
+func (l *Lazy) Mount() {
+	l.load()
+}
+
+// Updated implements the vecty.Updater interface.
+func (l *Lazy) Updated(prev vecty.Component) {
+	if p := prev.(*Lazy); p.Module != l.Module || p.Name != l.Name {
+		l.Unmount()
+		l.mount, l.err = nil, nil
+		l.load()
+		l.rerenderLazy()
+		return
+	}
+	l.attach()
+}
+
+// Unmount implements the vecty.Unmounter interface.
+func (l *Lazy) Unmount() {
+	if l.unmount != nil {
+		l.unmount()
+		l.unmount = nil
+	}
+}
+
+// load loads the module, and re-renders the component once loaded.
+func (l *Lazy) load() {
+	module, name := l.Module, l.Name
+	load := l.loadModule
+	if load == nil {
+		load = loadModule
+	}
+	load(module, name, func(mount mountFunc, err error) {
+		if module != l.Module || name != l.Name {
+			// Superseded by a load of another component.
+			return
+		}
+		l.mount, l.err = mount, err
+		l.rerenderLazy()
+	})
+}
+
+// rerenderLazy re-renders the component.
+func (l *Lazy) rerenderLazy() {
+	if l.rerender != nil {
+		l.rerender(l)
+		return
+	}
+	vecty.Rerender(l)
+}
+
+// attach renders the exported component into the container, once loaded.
+func (l *Lazy) attach() {
+	if l.mount == nil || l.unmount != nil || !l.container.Attached() {
+		return
+	}
+	l.unmount = l.mount(&l.container)
+}
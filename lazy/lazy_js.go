@@ -0,0 +1,170 @@
+// +build js
+
+package lazy
+
+import (
+	"errors"
+	"syscall/js"
+
+	"github.com/hexops/vecty"
+)
+
+// registry returns the registry of exported components, which is shared by all
+// modules of the page. It is built from plain objects, rather than evaluated
+// from source, so that pages whose Content Security Policy forbids eval may
+// use lazily loaded modules. It holds:
+//
+// - exports: the mount function exported under each name.
+// - waiting: the resolve functions of the promises waiting for each name to be
+//   exported.
+// - modules: the promise loading each module, by URL.
+func registry() js.Value {
+	r := js.Global().Get("__vectyLazy")
+	if r.Truthy() {
+		return r
+	}
+	object := js.Global().Get("Object")
+	r = object.New()
+	r.Set("exports", object.New())
+	r.Set("waiting", object.New())
+	r.Set("modules", object.New())
+	js.Global().Set("__vectyLazy", r)
+	return r
+}
+
+// Export exports the component returned by newComponent under the given name,
+// for Lazy components of other modules to render. Each Lazy component renders
+// its own instance of the component.
+//
+// Export must be called by the main function of the module, which must then
+// block forever (e.g. using select {}) for the component to remain usable.
+func Export(name string, newComponent func() vecty.Component) {
+	// The function is never released, as the component may be rendered for as
+	// long as the page is open.
+	mount := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		c := newComponent()
+		if err := vecty.RenderIntoContainerNode(args[0], c); err != nil {
+			panic(err)
+		}
+		var unmount js.Func
+		unmount = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			vecty.Unrender(c)
+			unmount.Release()
+			return nil
+		})
+		return unmount
+	})
+	r := registry()
+	r.Get("exports").Set(name, mount)
+	waiting := r.Get("waiting")
+	if resolves := waiting.Get(name); resolves.Truthy() {
+		for i := 0; i < resolves.Length(); i++ {
+			resolves.Index(i).Invoke(mount)
+		}
+		waiting.Delete(name)
+	}
+}
+
+// load returns a promise of the mount function exported under the given name
+// by the module at the given URL, which is loaded unless it already was. Failed
+// loads are retried by the next call.
+func load(module, name string) js.Value {
+	r := registry()
+	modules := r.Get("modules")
+	loaded := modules.Get(module)
+	if !loaded.Truthy() {
+		loaded = instantiate(module)
+		modules.Set(module, loaded)
+		// Allow failed loads to be retried.
+		var ok, failed js.Func
+		release := func() {
+			ok.Release()
+			failed.Release()
+		}
+		ok = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			release()
+			return nil
+		})
+		failed = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			release()
+			modules.Delete(module)
+			return nil
+		})
+		loaded.Call("then", ok, failed)
+	}
+	return then(loaded, func(js.Value) interface{} {
+		if mount := r.Get("exports").Get(name); mount.Truthy() {
+			return mount
+		}
+		var wait js.Func
+		wait = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			wait.Release()
+			waiting := r.Get("waiting")
+			resolves := waiting.Get(name)
+			if !resolves.Truthy() {
+				resolves = js.Global().Get("Array").New()
+				waiting.Set(name, resolves)
+			}
+			resolves.Call("push", args[0])
+			return nil
+		})
+		return js.Global().Get("Promise").New(wait)
+	})
+}
+
+// loadModule loads the module at the given URL, unless already loaded, and
+// calls done once it has exported the named component.
+func loadModule(module, name string, done func(mount mountFunc, err error)) {
+	var ok, failed js.Func
+	release := func() {
+		ok.Release()
+		failed.Release()
+	}
+	ok = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		exported := args[0]
+		done(func(container *vecty.Ref) func() {
+			unmount := exported.Invoke(container.Node())
+			return func() { unmount.Invoke() }
+		}, nil)
+		return nil
+	})
+	failed = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		reason := js.Global().Get("String").Invoke(args[0]).String()
+		done(nil, errors.New("lazy: failed to load module "+module+": "+reason))
+		return nil
+	})
+	load(module, name).Call("then", ok, failed)
+}
+
+// instantiate returns a promise which resolves once the module at the given
+// URL has been fetched and started, using the Go class of wasm_exec.js.
+func instantiate(module string) js.Value {
+	g := js.Global().Get("Go").New()
+	fetched := js.Global().Call("fetch", module)
+	instantiated := js.Global().Get("WebAssembly").Call("instantiateStreaming", fetched, g.Get("importObject"))
+	return then(instantiated, func(result js.Value) interface{} {
+		g.Call("run", result.Get("instance"))
+		return nil
+	})
+}
+
+// then returns a promise of the result of f given the value of the promise p,
+// which is rejected with the reason p is rejected with.
+func then(p js.Value, f func(v js.Value) interface{}) js.Value {
+	var fulfilled, rejected js.Func
+	release := func() {
+		fulfilled.Release()
+		rejected.Release()
+	}
+	fulfilled = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		return f(args[0])
+	})
+	rejected = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		release()
+		return js.Global().Get("Promise").Call("reject", args[0])
+	})
+	return p.Call("then", fulfilled, rejected)
+}
@@ -0,0 +1,26 @@
+// +build !js
+
+package lazy
+
+import "github.com/hexops/vecty"
+
+// Export exports the component returned by newComponent under the given name,
+// for Lazy components of other modules to render. Each Lazy component renders
+// its own instance of the component.
+//
+// Export must be called by the main function of the module, which must then
+// block forever (e.g. using select {}) for the component to remain usable.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics.
+func Export(name string, newComponent func() vecty.Component) {
+	panic("lazy: Export is only supported when running inside a browser")
+}
+
+// loadModule loads the module at the given URL, unless already loaded, and
+// calls done once it has exported the named component. Outside of a browser it
+// panics.
+func loadModule(module, name string, done func(mount mountFunc, err error)) {
+	panic("lazy: loading modules is only supported when running inside a browser")
+}
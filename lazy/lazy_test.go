@@ -0,0 +1,116 @@
+package lazy
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+)
+
+func TestLazy_content(t *testing.T) {
+	placeholder, failed := elem.Div(), elem.Div()
+	l := &Lazy{}
+	if got := l.content(); got != nil {
+		t.Fatalf("got %v want no content without Placeholder", got)
+	}
+	l.Placeholder = func() vecty.ComponentOrHTML { return placeholder }
+	if got := l.content(); got != placeholder {
+		t.Fatalf("got %v want placeholder while loading", got)
+	}
+
+	l.err = errors.New("lazy: failed to load module admin.wasm")
+	if got := l.content(); got != placeholder {
+		t.Fatalf("got %v want placeholder on error without Error", got)
+	}
+	l.Error = func(err error) vecty.ComponentOrHTML {
+		if err != l.err {
+			t.Fatalf("got error %v want %v", err, l.err)
+		}
+		return failed
+	}
+	if got := l.content(); got != failed {
+		t.Fatalf("got %v want Error render", got)
+	}
+
+	l.err, l.mount = nil, func(container *vecty.Ref) func() { return func() {} }
+	if got := l.content(); got != nil {
+		t.Fatalf("got %v want no content once loaded", got)
+	}
+}
+
+// TestLazy_Unmount tests that unmounting unrenders the exported component,
+// and that it is only rendered once attached.
+func TestLazy_Unmount(t *testing.T) {
+	var mounted, unmounted int
+	l := &Lazy{}
+	l.mount = func(container *vecty.Ref) func() {
+		mounted++
+		return func() { unmounted++ }
+	}
+	l.attach()
+	if mounted != 0 {
+		t.Fatalf("mounted %d times want 0 before the container is attached", mounted)
+	}
+	l.unmount = func() { unmounted++ }
+	l.attach()
+	l.Unmount()
+	l.Unmount()
+	if mounted != 0 || unmounted != 1 {
+		t.Fatalf("got mounted %d unmounted %d want 0 and 1", mounted, unmounted)
+	}
+}
+
+// TestLazy_rerender tests that each re-render of a Lazy component, whose module
+// failed to load or which switched modules, renders new content, as Vecty
+// panics if a component renders the same *vecty.HTML twice.
+func TestLazy_rerender(t *testing.T) {
+	var (
+		loads    []func(mount mountFunc, err error)
+		modules  []string
+		contents []vecty.ComponentOrHTML
+	)
+	l := &Lazy{
+		Module: "admin.wasm",
+		Name:   "admin",
+		Placeholder: func() vecty.ComponentOrHTML {
+			return elem.Div(vecty.Text("Loading..."))
+		},
+	}
+	l.loadModule = func(module, name string, done func(mount mountFunc, err error)) {
+		modules = append(modules, module)
+		loads = append(loads, done)
+	}
+	l.rerender = func(c vecty.Component) {
+		if c != l {
+			t.Fatalf("got %v rerendered want Lazy", c)
+		}
+		content := l.content()
+		for _, prev := range contents {
+			if content != nil && content == prev {
+				t.Fatal("got the same content rendered twice")
+			}
+		}
+		contents = append(contents, content)
+	}
+	contents = append(contents, l.content())
+
+	l.Mount()
+	loads[0](nil, errors.New("lazy: failed to load module admin.wasm"))
+
+	// Switching modules loads the other module, rendering the placeholder
+	// again whilst it loads.
+	prev := *l
+	l.Module = "settings.wasm"
+	l.Updated(&prev)
+	if len(modules) != 2 || modules[1] != "settings.wasm" {
+		t.Fatalf("got modules %v loaded want admin.wasm and settings.wasm", modules)
+	}
+
+	// Loads superseded by another do not re-render.
+	loads[0](nil, nil)
+	loads[1](func(container *vecty.Ref) func() { return func() {} }, nil)
+	if len(contents) != 4 || contents[3] != nil {
+		t.Fatalf("got %d renders want 4, the last without content once loaded", len(contents))
+	}
+}
@@ -111,6 +111,35 @@ func Property(key string, value interface{}) Applyer {
 	})
 }
 
+// Controlled returns an Applyer which makes an input, textarea or select
+// element a controlled input, whose value is always the one last rendered.
+//
+// Like any element, its value and checked properties are only set when they
+// differ from those of the live DOM node. Additionally, for controlled inputs:
+//
+// - When the value is set whilst the element has focus, its selection range
+//   (and thus the cursor position) is preserved.
+// - Whilst the user is composing text via an input method editor (IME), the
+//   value is not set, so that the composition is not interrupted.
+// - For select elements, the value and selectedIndex properties are set once
+//   their options have been rendered, so that they may refer to them.
+func Controlled() Applyer {
+	return markupFunc(func(h *HTML) {
+		if h.controlled != nil {
+			return
+		}
+		h.controlled = &controlledState{}
+		h.eventListeners = append(h.eventListeners,
+			&EventListener{Name: "compositionstart", Listener: func(*Event) {
+				h.controlled.composing = true
+			}},
+			&EventListener{Name: "compositionend", Listener: func(*Event) {
+				h.controlled.composing = false
+			}},
+		)
+	})
+}
+
 // Attribute returns an Applyer which applies the given attribute to an element.
 //
 // In most situations, you should use Property function, or the prop subpackage
@@ -189,6 +218,64 @@ func (m ClassMap) Apply(h *HTML) {
 	}
 }
 
+// Ref is markup that refers to the DOM node of the element it is applied to.
+// It is set when the element's DOM node is created, and cleared when the
+// element is unmounted:
+//
+// 	type MyComponent struct {
+// 		vecty.Core
+// 		input vecty.Ref
+// 	}
+//
+// 	func (c *MyComponent) Mount() {
+// 		c.input.Node().Call("focus")
+// 	}
+//
+// 	func (c *MyComponent) Render() vecty.ComponentOrHTML {
+// 		return elem.Input(vecty.Markup(&c.input))
+// 	}
+//
+// Unlike an *HTML returned from a previous Render, a Ref may be kept and used
+// across renders, for as long as the element it is applied to is rendered.
+type Ref struct {
+	node jsObject
+}
+
+// Apply implements the Applyer interface.
+func (r *Ref) Apply(h *HTML) {
+	h.ref = r
+}
+
+// Attached reports whether the Ref refers to a DOM node, i.e. whether the
+// element it is applied to is currently rendered.
+func (r *Ref) Attached() bool {
+	return r.node != nil
+}
+
+// EnterLeave is markup which is notified when the element it is applied to
+// enters and leaves the DOM, e.g. to animate it. The transition subpackage
+// provides ready-made CSS and Web Animations transitions built upon it.
+type EnterLeave struct {
+	// Enter, if non-nil, is called when a new DOM node is created for the
+	// element, including on the initial render, with its properties set but
+	// before it is inserted into the DOM.
+	Enter func(h *HTML)
+
+	// Leave, if non-nil, is called when the element is removed by a re-render,
+	// once it is unmounted. Its DOM node is only removed from the DOM once
+	// done is called, such that it may be animated out of the page; until
+	// then, it remains in place amongst the nodes of its siblings.
+	//
+	// Leave is not called for the elements within a removed element, nor when
+	// the whole tree is unrendered.
+	Leave func(h *HTML, done func())
+}
+
+// Apply implements the Applyer interface.
+func (e EnterLeave) Apply(h *HTML) {
+	h.enter, h.leave = e.Enter, e.Leave
+}
+
 // MarkupList represents a list of Applyer which is individually
 // applied to an HTML element or text node.
 //
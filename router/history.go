@@ -0,0 +1,147 @@
+package router
+
+import "strings"
+
+// History is the navigation backend used by Router, Link and Navigate. It
+// tracks the current path and notifies listeners whenever it changes.
+type History interface {
+	// Path returns the current path, e.g. "/users/123".
+	Path() string
+
+	// Push navigates to the given path, adding a new entry to the history.
+	Push(path string)
+
+	// Replace navigates to the given path, replacing the current entry in the
+	// history.
+	Replace(path string)
+
+	// Href returns the value to be used as the href attribute of a link to
+	// the given path.
+	Href(path string) string
+
+	// Listen registers fn to be invoked whenever the current path changes,
+	// including through Push, Replace, and the browser's back and forward
+	// buttons. The returned function unregisters the listener.
+	Listen(fn func()) (unlisten func())
+}
+
+var currentHistory History
+
+// SetHistory sets the History used by Router, Link and Navigate. It should be
+// called before the first Router is rendered.
+func SetHistory(h History) {
+	currentHistory = h
+}
+
+// CurrentHistory returns the History set via SetHistory. If none has been
+// set, a browser History (see NewBrowserHistory) is used.
+func CurrentHistory() History {
+	if currentHistory == nil {
+		currentHistory = NewBrowserHistory()
+	}
+	return currentHistory
+}
+
+// Navigate navigates to the given path using the current History, adding a
+// new entry to the history.
+func Navigate(path string) {
+	CurrentHistory().Push(path)
+}
+
+// listeners is a registry of change listeners, shared by History
+// implementations.
+type listeners struct {
+	nextID int
+	fns    map[int]func()
+}
+
+// add registers fn and returns a function which unregisters it.
+func (l *listeners) add(fn func()) (remove func()) {
+	if l.fns == nil {
+		l.fns = make(map[int]func())
+	}
+	l.nextID++
+	id := l.nextID
+	l.fns[id] = fn
+	return func() { delete(l.fns, id) }
+}
+
+// fire invokes all registered listeners.
+func (l *listeners) fire() {
+	for _, fn := range l.fns {
+		fn()
+	}
+}
+
+// MemoryHistory is a History which keeps its entries in memory, without
+// touching the browser location. It is useful for tests and for non-browser
+// environments.
+type MemoryHistory struct {
+	entries   []string
+	index     int
+	listeners listeners
+}
+
+// NewMemoryHistory returns a new MemoryHistory whose current path is the
+// given path.
+func NewMemoryHistory(path string) *MemoryHistory {
+	return &MemoryHistory{entries: []string{path}}
+}
+
+// Path implements the History interface.
+func (m *MemoryHistory) Path() string {
+	return m.entries[m.index]
+}
+
+// Push implements the History interface.
+func (m *MemoryHistory) Push(path string) {
+	m.entries = append(m.entries[:m.index+1], path)
+	m.index++
+	m.listeners.fire()
+}
+
+// Replace implements the History interface.
+func (m *MemoryHistory) Replace(path string) {
+	m.entries[m.index] = path
+	m.listeners.fire()
+}
+
+// Href implements the History interface.
+func (m *MemoryHistory) Href(path string) string {
+	return path
+}
+
+// Listen implements the History interface.
+func (m *MemoryHistory) Listen(fn func()) (unlisten func()) {
+	return m.listeners.add(fn)
+}
+
+// Back navigates to the previous entry, if any, as the browser's back button
+// would.
+func (m *MemoryHistory) Back() {
+	if m.index == 0 {
+		return
+	}
+	m.index--
+	m.listeners.fire()
+}
+
+// Forward navigates to the next entry, if any, as the browser's forward
+// button would.
+func (m *MemoryHistory) Forward() {
+	if m.index == len(m.entries)-1 {
+		return
+	}
+	m.index++
+	m.listeners.fire()
+}
+
+// hashPath returns the path represented by the given URL fragment identifier
+// (e.g. "#/users/123"), as used by NewHashHistory.
+func hashPath(hash string) string {
+	path := strings.TrimPrefix(hash, "#")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return path
+}
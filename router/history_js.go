@@ -0,0 +1,115 @@
+// +build js
+
+package router
+
+import "syscall/js"
+
+// browserHistory is a History backed by the browser's History API.
+type browserHistory struct {
+	listeners listeners
+	onPopState js.Func
+}
+
+// NewBrowserHistory returns a History backed by the browser's History API
+// (pushState and the popstate event), such that paths appear in the URL as
+// e.g. "https://example.com/users/123".
+//
+// The server must respond to every routed path with the application, or deep
+// links will not survive a reload.
+func NewBrowserHistory() History {
+	h := &browserHistory{}
+	h.onPopState = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		h.listeners.fire()
+		return nil
+	})
+	js.Global().Call("addEventListener", "popstate", h.onPopState)
+	return h
+}
+
+// Path implements the History interface.
+func (h *browserHistory) Path() string {
+	return js.Global().Get("location").Get("pathname").String()
+}
+
+// Push implements the History interface.
+func (h *browserHistory) Push(path string) {
+	if path == h.Path() {
+		return
+	}
+	js.Global().Get("history").Call("pushState", nil, "", path)
+	h.listeners.fire()
+}
+
+// Replace implements the History interface.
+func (h *browserHistory) Replace(path string) {
+	js.Global().Get("history").Call("replaceState", nil, "", path)
+	h.listeners.fire()
+}
+
+// Href implements the History interface.
+func (h *browserHistory) Href(path string) string {
+	return path
+}
+
+// Listen implements the History interface.
+func (h *browserHistory) Listen(fn func()) (unlisten func()) {
+	return h.listeners.add(fn)
+}
+
+// hashHistory is a History backed by the URL's fragment identifier.
+type hashHistory struct {
+	listeners    listeners
+	onHashChange js.Func
+}
+
+// NewHashHistory returns a History backed by the URL's fragment identifier,
+// such that paths appear in the URL as e.g.
+// "https://example.com/#/users/123".
+//
+// Unlike NewBrowserHistory it requires no server support, which makes it
+// suitable for static hosting: the server only ever sees requests for "/", yet
+// deep links survive a reload.
+//
+// Route declarations need not change to switch between the two, just pass the
+// desired History to SetHistory.
+func NewHashHistory() History {
+	h := &hashHistory{}
+	h.onHashChange = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		h.listeners.fire()
+		return nil
+	})
+	js.Global().Call("addEventListener", "hashchange", h.onHashChange)
+	return h
+}
+
+// Path implements the History interface.
+func (h *hashHistory) Path() string {
+	return hashPath(js.Global().Get("location").Get("hash").String())
+}
+
+// Push implements the History interface.
+func (h *hashHistory) Push(path string) {
+	if path == h.Path() {
+		return
+	}
+	// pushState does not fire the hashchange event, so we notify listeners
+	// ourselves.
+	js.Global().Get("history").Call("pushState", nil, "", h.Href(path))
+	h.listeners.fire()
+}
+
+// Replace implements the History interface.
+func (h *hashHistory) Replace(path string) {
+	js.Global().Get("history").Call("replaceState", nil, "", h.Href(path))
+	h.listeners.fire()
+}
+
+// Href implements the History interface.
+func (h *hashHistory) Href(path string) string {
+	return "#" + path
+}
+
+// Listen implements the History interface.
+func (h *hashHistory) Listen(fn func()) (unlisten func()) {
+	return h.listeners.add(fn)
+}
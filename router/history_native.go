@@ -0,0 +1,35 @@
+// +build !js
+
+package router
+
+// NewBrowserHistory returns a History backed by the browser's History API
+// (pushState and the popstate event), such that paths appear in the URL as
+// e.g. "https://example.com/users/123".
+//
+// The server must respond to every routed path with the application, or deep
+// links will not survive a reload.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics. Use NewMemoryHistory instead.
+func NewBrowserHistory() History {
+	panic("router: NewBrowserHistory is only supported when running inside a browser")
+}
+
+// NewHashHistory returns a History backed by the URL's fragment identifier,
+// such that paths appear in the URL as e.g.
+// "https://example.com/#/users/123".
+//
+// Unlike NewBrowserHistory it requires no server support, which makes it
+// suitable for static hosting: the server only ever sees requests for "/", yet
+// deep links survive a reload.
+//
+// Route declarations need not change to switch between the two, just pass the
+// desired History to SetHistory.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics. Use NewMemoryHistory instead.
+func NewHashHistory() History {
+	panic("router: NewHashHistory is only supported when running inside a browser")
+}
@@ -0,0 +1,247 @@
+// Package router provides client-side routing for Vecty applications.
+//
+// A Router renders the Route matching the current path, and re-renders
+// whenever the path changes:
+//
+// 	&router.Router{
+// 		Routes: []*router.Route{
+// 			{Pattern: "/", Render: renderHome},
+// 			{Pattern: "/users", Render: renderUsers, Routes: []*router.Route{
+// 				{Pattern: ":id", Render: renderUser},
+// 			}},
+// 		},
+// 		NotFound: renderNotFound,
+// 	}
+//
+// Navigation happens through Link components, or by calling Navigate. Both
+// go through the History set via SetHistory, which defaults to the browser's
+// History API. For static hosting without URL rewrite rules, routes can
+// instead be kept in the URL's fragment identifier:
+//
+// 	router.SetHistory(router.NewHashHistory())
+package router
+
+import (
+	"strings"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+	"github.com/hexops/vecty/event"
+	"github.com/hexops/vecty/prop"
+)
+
+// Route describes how to render a path matching a pattern.
+type Route struct {
+	// Pattern is matched against the path, segment by segment. A segment
+	// beginning with ':' matches any single segment and captures it as a
+	// parameter of the same name (e.g. "/users/:id"). A final segment "*"
+	// matches the remainder of the path, which is captured as the parameter
+	// "*".
+	//
+	// The patterns of nested routes are relative to their parent's pattern.
+	Pattern string
+
+	// Render renders the route. For routes with nested routes, the rendered
+	// nested route is available as Context.Outlet.
+	Render func(ctx *Context) vecty.ComponentOrHTML
+
+	// Routes are nested routes, matched against the remainder of the path
+	// after Pattern. If none match and the path has no remainder, the route is
+	// rendered with a nil Context.Outlet.
+	Routes []*Route
+}
+
+// Context describes the route being rendered.
+type Context struct {
+	// Path is the full path that was matched.
+	Path string
+
+	// Params holds the parameters captured by the matched route and all of
+	// its parents.
+	Params map[string]string
+
+	// Outlet is the rendered nested route, or nil if there is none.
+	Outlet vecty.ComponentOrHTML
+}
+
+// Param returns the named parameter, or an empty string if it was not
+// captured.
+func (c *Context) Param(name string) string {
+	return c.Params[name]
+}
+
+// Match finds the chain of routes matching the given path, outermost first,
+// along with the captured parameters. If no route matches, it returns nil.
+func Match(routes []*Route, path string) (chain []*Route, params map[string]string) {
+	params = make(map[string]string)
+	chain = match(routes, splitPath(path), params)
+	if chain == nil {
+		return nil, nil
+	}
+	return chain, params
+}
+
+// match recursively matches the given path segments against routes, storing
+// captured parameters into params.
+func match(routes []*Route, segments []string, params map[string]string) []*Route {
+	for _, r := range routes {
+		captured := make(map[string]string)
+		rest, ok := matchPattern(splitPath(r.Pattern), segments, captured)
+		if !ok {
+			continue
+		}
+		var chain []*Route
+		if len(r.Routes) > 0 {
+			chain = match(r.Routes, rest, captured)
+		}
+		if chain == nil && len(rest) > 0 {
+			continue
+		}
+		for k, v := range captured {
+			params[k] = v
+		}
+		return append([]*Route{r}, chain...)
+	}
+	return nil
+}
+
+// matchPattern matches the pattern segments against a prefix of the path
+// segments, and returns the remaining path segments.
+func matchPattern(pattern, segments []string, params map[string]string) (rest []string, ok bool) {
+	for i, p := range pattern {
+		if p == "*" {
+			params["*"] = strings.Join(segments[i:], "/")
+			return nil, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+		switch {
+		case strings.HasPrefix(p, ":"):
+			params[p[1:]] = segments[i]
+		case p != segments[i]:
+			return nil, false
+		}
+	}
+	return segments[len(pattern):], true
+}
+
+// splitPath splits the path into its non-empty segments.
+func splitPath(path string) []string {
+	var segments []string
+	for _, s := range strings.Split(path, "/") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+	return segments
+}
+
+// Router is a vecty.Component which renders the route matching the current
+// path of the History (see SetHistory), and re-renders itself whenever the
+// path changes.
+type Router struct {
+	vecty.Core
+
+	// Routes are the routes to match, in order of precedence.
+	Routes []*Route `vecty:"prop"`
+
+	// NotFound renders the path when no route matches. If nil, nothing is
+	// rendered.
+	NotFound func(ctx *Context) vecty.ComponentOrHTML `vecty:"prop"`
+
+	unlisten func()
+}
+
+// Render implements the vecty.Component interface.
+func (r *Router) Render() vecty.ComponentOrHTML {
+	path := CurrentHistory().Path()
+	chain, params := Match(r.Routes, path)
+	if chain == nil {
+		if r.NotFound == nil {
+			return nil
+		}
+		return r.NotFound(&Context{Path: path, Params: map[string]string{}})
+	}
+
+	// Render from the innermost route outwards, so that each route can place
+	// its nested route's render.
+	var outlet vecty.ComponentOrHTML
+	for i := len(chain) - 1; i >= 0; i-- {
+		if chain[i].Render == nil {
+			continue
+		}
+		outlet = chain[i].Render(&Context{
+			Path:   path,
+			Params: params,
+			Outlet: outlet,
+		})
+	}
+	return outlet
+}
+
+// Mount implements the vecty.Mounter interface.
+func (r *Router) Mount() {
+	r.unlisten = CurrentHistory().Listen(func() {
+		vecty.Rerender(r)
+	})
+}
+
+// Unmount implements the vecty.Unmounter interface.
+func (r *Router) Unmount() {
+	if r.unlisten != nil {
+		r.unlisten()
+		r.unlisten = nil
+	}
+}
+
+// Link is a vecty.Component which renders an anchor to the given path. Clicks
+// on it navigate through the current History rather than reloading the page.
+//
+// Clicks with a modifier key held (e.g. to open the link in a new tab), or
+// with a button other than the main one, are left to the browser.
+type Link struct {
+	vecty.Core
+
+	// To is the path to link to.
+	To string `vecty:"prop"`
+
+	// Replace specifies whether to replace the current history entry rather
+	// than adding a new one.
+	Replace bool `vecty:"prop"`
+
+	// Markup is applied to the anchor element.
+	Markup []vecty.Applyer `vecty:"prop"`
+
+	// Children are the children of the anchor element.
+	Children vecty.List `vecty:"prop"`
+}
+
+func (l *Link) onClick(e *vecty.Event) {
+	if e.Value.Get("button").Int() != 0 {
+		return
+	}
+	for _, key := range []string{"altKey", "ctrlKey", "metaKey", "shiftKey"} {
+		if e.Value.Get(key).Bool() {
+			return
+		}
+	}
+	e.Value.Call("preventDefault")
+	if l.Replace {
+		CurrentHistory().Replace(l.To)
+		return
+	}
+	CurrentHistory().Push(l.To)
+}
+
+// Render implements the vecty.Component interface.
+func (l *Link) Render() vecty.ComponentOrHTML {
+	return elem.Anchor(
+		vecty.Markup(
+			prop.Href(CurrentHistory().Href(l.To)),
+			event.Click(l.onClick),
+		),
+		vecty.Markup(l.Markup...),
+		l.Children,
+	)
+}
@@ -0,0 +1,178 @@
+package router
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hexops/vecty"
+)
+
+func TestMatch(t *testing.T) {
+	var (
+		home     = &Route{Pattern: "/"}
+		user     = &Route{Pattern: ":id"}
+		settings = &Route{Pattern: ":id/settings"}
+		index    = &Route{Pattern: ""}
+		users    = &Route{Pattern: "/users", Routes: []*Route{settings, user}}
+		files    = &Route{Pattern: "/files/*"}
+		admin    = &Route{Pattern: "/admin", Routes: []*Route{index}}
+		routes   = []*Route{home, users, files, admin}
+	)
+	cases := []struct {
+		path       string
+		wantChain  []*Route
+		wantParams map[string]string
+	}{
+		{
+			path:       "/",
+			wantChain:  []*Route{home},
+			wantParams: map[string]string{},
+		},
+		{
+			path:       "/users",
+			wantChain:  []*Route{users},
+			wantParams: map[string]string{},
+		},
+		{
+			path:       "/users/123",
+			wantChain:  []*Route{users, user},
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			path:       "/users/123/",
+			wantChain:  []*Route{users, user},
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			path:       "/users/123/settings",
+			wantChain:  []*Route{users, settings},
+			wantParams: map[string]string{"id": "123"},
+		},
+		{
+			path:       "/files/a/b/c.txt",
+			wantChain:  []*Route{files},
+			wantParams: map[string]string{"*": "a/b/c.txt"},
+		},
+		{
+			path:       "/admin",
+			wantChain:  []*Route{admin, index},
+			wantParams: map[string]string{},
+		},
+		{
+			path: "/users/123/unknown",
+		},
+		{
+			path: "/unknown",
+		},
+	}
+	for _, tst := range cases {
+		t.Run(tst.path, func(t *testing.T) {
+			chain, params := Match(routes, tst.path)
+			if !reflect.DeepEqual(chain, tst.wantChain) {
+				t.Fatalf("got chain %v want %v", chain, tst.wantChain)
+			}
+			if !reflect.DeepEqual(params, tst.wantParams) {
+				t.Fatalf("got params %v want %v", params, tst.wantParams)
+			}
+		})
+	}
+}
+
+func TestRouter_Render(t *testing.T) {
+	var (
+		userRender     = vecty.Text("user")
+		notFoundRender = vecty.Text("not found")
+		gotOutlet      vecty.ComponentOrHTML
+		gotID          string
+	)
+	r := &Router{
+		Routes: []*Route{
+			{
+				Pattern: "/users",
+				Render: func(ctx *Context) vecty.ComponentOrHTML {
+					gotOutlet = ctx.Outlet
+					return ctx.Outlet
+				},
+				Routes: []*Route{
+					{
+						Pattern: ":id",
+						Render: func(ctx *Context) vecty.ComponentOrHTML {
+							gotID = ctx.Param("id")
+							return userRender
+						},
+					},
+				},
+			},
+		},
+		NotFound: func(ctx *Context) vecty.ComponentOrHTML {
+			return notFoundRender
+		},
+	}
+
+	h := NewMemoryHistory("/users/42")
+	SetHistory(h)
+	defer SetHistory(nil)
+
+	if got := r.Render(); got != userRender {
+		t.Fatalf("got render %v want %v", got, userRender)
+	}
+	if gotOutlet != userRender {
+		t.Fatalf("got outlet %v want %v", gotOutlet, userRender)
+	}
+	if gotID != "42" {
+		t.Fatalf("got id %q want %q", gotID, "42")
+	}
+
+	h.Push("/missing")
+	if got := r.Render(); got != notFoundRender {
+		t.Fatalf("got render %v want %v", got, notFoundRender)
+	}
+}
+
+func TestMemoryHistory(t *testing.T) {
+	h := NewMemoryHistory("/a")
+	var fired int
+	unlisten := h.Listen(func() { fired++ })
+
+	h.Push("/b")
+	h.Push("/c")
+	h.Back()
+	if got, want := h.Path(), "/b"; got != want {
+		t.Fatalf("got path %q want %q", got, want)
+	}
+	h.Replace("/d")
+	h.Back()
+	h.Forward()
+	if got, want := h.Path(), "/d"; got != want {
+		t.Fatalf("got path %q want %q", got, want)
+	}
+	h.Push("/e") // discards the forward entry "/c".
+	h.Forward()  // no-op, already at the last entry.
+	if got, want := h.Path(), "/e"; got != want {
+		t.Fatalf("got path %q want %q", got, want)
+	}
+	if fired != 7 {
+		t.Fatalf("got %d change notifications want 7", fired)
+	}
+
+	unlisten()
+	h.Push("/f")
+	if fired != 7 {
+		t.Fatalf("got %d change notifications after unlisten want 7", fired)
+	}
+}
+
+func TestHashPath(t *testing.T) {
+	cases := map[string]string{
+		"":           "/",
+		"#":          "/",
+		"#/":         "/",
+		"#/users/42": "/users/42",
+		"#users":     "/users",
+	}
+	for hash, want := range cases {
+		if got := hashPath(hash); got != want {
+			t.Errorf("hashPath(%q) = %q want %q", hash, got, want)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package vecty
+
+import (
+	"sync"
+	"time"
+)
+
+// Scheduler schedules the rendering of components queued by Rerender. By
+// default, AnimationFrameScheduler is used; SetScheduler changes it.
+type Scheduler interface {
+	// Schedule arranges for render to be called once, at a suitable time for
+	// rendering (a "frame"), with the time at which the frame started as per
+	// performance.now(). It may be called from any goroutine, and is never
+	// called with a lock held, so render may be called before it returns.
+	Schedule(render func(startTime float64))
+}
+
+// SetScheduler sets the Scheduler used to schedule the rendering of components
+// queued by Rerender. It should be called before rendering anything.
+func SetScheduler(s Scheduler) {
+	if s == nil {
+		panic("vecty: SetScheduler illegally called with a nil Scheduler")
+	}
+	renderLoop.mu.Lock()
+	defer renderLoop.mu.Unlock()
+	renderLoop.scheduler = s
+}
+
+// FrameBudget limits how much rendering is done in a single frame, so that
+// rendering many components does not cause the page to stutter. Components
+// which do not fit into a frame's budget are rendered in the next frame.
+type FrameBudget struct {
+	// Duration is the time available for rendering in each frame. If zero,
+	// all queued components are rendered in a single frame.
+	Duration time.Duration
+
+	// Headroom is how many times the average render time of the components
+	// rendered so far in a frame must remain of its Duration, for another
+	// component to be rendered in the frame.
+	Headroom float64
+}
+
+// DefaultFrameBudget is the FrameBudget used unless SetFrameBudget is called,
+// targeting displays refreshing at 60Hz.
+var DefaultFrameBudget = FrameBudget{
+	Duration: time.Second / 60,
+	Headroom: 2,
+}
+
+// SetFrameBudget sets the FrameBudget which limits how much rendering is done
+// in a single frame, e.g. to target displays refreshing at 120Hz:
+//
+// 	vecty.SetFrameBudget(vecty.FrameBudget{Duration: time.Second / 120, Headroom: 2})
+func SetFrameBudget(b FrameBudget) {
+	renderLoop.mu.Lock()
+	defer renderLoop.mu.Unlock()
+	renderLoop.budget = b
+}
+
+// animationFrameScheduler schedules via requestAnimationFrame.
+type animationFrameScheduler struct{}
+
+// AnimationFrameScheduler returns the default Scheduler, which renders before
+// the browser's next repaint via requestAnimationFrame.
+func AnimationFrameScheduler() Scheduler {
+	return animationFrameScheduler{}
+}
+
+// Schedule implements the Scheduler interface.
+func (animationFrameScheduler) Schedule(render func(startTime float64)) {
+	requestAnimationFrame(render)
+}
+
+// microtaskScheduler schedules via queueMicrotask.
+type microtaskScheduler struct{}
+
+// MicrotaskScheduler returns a Scheduler which renders as soon as the current
+// task (e.g. an event listener) completes, via queueMicrotask. Renders are not
+// aligned with the browser's repaints, which makes it suitable for
+// environments without requestAnimationFrame, such as workers.
+func MicrotaskScheduler() Scheduler {
+	return microtaskScheduler{}
+}
+
+// Schedule implements the Scheduler interface.
+func (microtaskScheduler) Schedule(render func(startTime float64)) {
+	var cb jsFunc
+	cb = funcOf(func(this jsObject, args []jsObject) interface{} {
+		cb.Release()
+
+		render(now())
+		return undefined()
+	})
+	global().Call("queueMicrotask", cb)
+}
+
+// timeoutScheduler schedules via setTimeout.
+type timeoutScheduler struct {
+	delay time.Duration
+}
+
+// TimeoutScheduler returns a Scheduler which renders after the given delay,
+// via setTimeout. Like MicrotaskScheduler, it is suitable for environments
+// without requestAnimationFrame, and additionally allows rendering at a fixed
+// rate (e.g. to batch more renders together).
+func TimeoutScheduler(delay time.Duration) Scheduler {
+	return timeoutScheduler{delay: delay}
+}
+
+// Schedule implements the Scheduler interface.
+func (s timeoutScheduler) Schedule(render func(startTime float64)) {
+	var cb jsFunc
+	cb = funcOf(func(this jsObject, args []jsObject) interface{} {
+		cb.Release()
+
+		render(now())
+		return undefined()
+	})
+	global().Call("setTimeout", cb, milliseconds(s.delay))
+}
+
+// ManualScheduler is a Scheduler which only renders when its Run method is
+// called, e.g. by a custom event loop, or by tests which render
+// deterministically.
+type ManualScheduler struct {
+	mu      sync.Mutex
+	pending []func(startTime float64)
+}
+
+// Schedule implements the Scheduler interface.
+func (s *ManualScheduler) Schedule(render func(startTime float64)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending = append(s.pending, render)
+}
+
+// Run runs the frames scheduled so far, and reports whether there were any.
+// Frames scheduled whilst running are left for the next call.
+func (s *ManualScheduler) Run() bool {
+	s.mu.Lock()
+	pending := s.pending
+	s.pending = nil
+	s.mu.Unlock()
+	for _, render := range pending {
+		render(now())
+	}
+	return len(pending) > 0
+}
+
+// now returns the current time as per performance.now().
+func now() float64 {
+	return global().Get("performance").Call("now").Float()
+}
+
+// milliseconds returns the duration in (fractional) milliseconds.
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
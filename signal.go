@@ -0,0 +1,251 @@
+package vecty
+
+import (
+	"sync"
+
+	"github.com/hexops/vecty/internal/identical"
+)
+
+// Signal is a reactive value. Components which read a Signal via Get during
+// their Render method are re-rendered (via Rerender) whenever its value is
+// changed via Set, without the need to call Rerender at every mutation site:
+//
+// 	var count = vecty.NewSignal(0)
+//
+// 	func (c *Counter) Render() vecty.ComponentOrHTML {
+// 		return elem.Button(
+// 			vecty.Markup(event.Click(func(*vecty.Event) {
+// 				count.Set(count.Get().(int) + 1)
+// 			})),
+// 			vecty.Text(strconv.Itoa(count.Get().(int))),
+// 		)
+// 	}
+//
+// Only the components which read the Signal are re-rendered.
+//
+// A Signal may be Set, and read via Peek, from any goroutine, e.g. one reading
+// messages from a WebSocket. Get however makes whatever is being rendered or
+// computed at the time dependent on the Signal, so it is meant to be called by
+// Render methods and Computed functions.
+type Signal struct {
+	value      interface{}
+	dependents dependents
+}
+
+// NewSignal returns a new Signal holding the given value.
+func NewSignal(value interface{}) *Signal {
+	return &Signal{value: value}
+}
+
+// Get returns the current value of the Signal. If called during the Render
+// method of a Component, or during the computation of a Computed, that
+// Component or Computed becomes dependent on the Signal.
+func (s *Signal) Get() interface{} {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	s.dependents.track()
+	return s.value
+}
+
+// Peek returns the current value of the Signal, like Get, but without
+// creating a dependency on the Signal.
+func (s *Signal) Peek() interface{} {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	return s.value
+}
+
+// Set changes the value of the Signal, and re-renders the Components that
+// depend on it.
+//
+// If the new value is identical to the current one (as per the == operator),
+// Set is no-op. Values of uncomparable types, such as slices and maps, are
+// never identical, so that a slice modified in-place may be Set again.
+func (s *Signal) Set(value interface{}) {
+	signalMu.Lock()
+	if identical.Values(s.value, value) {
+		signalMu.Unlock()
+		return
+	}
+	s.value = value
+	var rerender []Component
+	s.dependents.invalidate(&rerender)
+	signalMu.Unlock()
+	rerenderDependents(rerender)
+}
+
+// Computed is a reactive value derived from Signals and other Computeds. Its
+// value is computed lazily, and cached until one of the values it was
+// computed from changes.
+//
+// Like a Signal, Components which read a Computed via Get during their Render
+// method are re-rendered whenever it changes.
+type Computed struct {
+	compute    func() interface{}
+	value      interface{}
+	valid      bool
+	dependents dependents
+
+	// invalidations counts the invalidations of the Computed, such that a
+	// value computed whilst it was invalidated is not cached.
+	invalidations int
+}
+
+// NewComputed returns a new Computed whose value is the result of compute.
+func NewComputed(compute func() interface{}) *Computed {
+	return &Computed{compute: compute}
+}
+
+// Get returns the current value of the Computed, computing it first if
+// necessary. If called during the Render method of a Component, or during the
+// computation of another Computed, that Component or Computed becomes
+// dependent on this Computed.
+func (c *Computed) Get() interface{} {
+	signalMu.Lock()
+	c.dependents.track()
+	if c.valid {
+		defer signalMu.Unlock()
+		return c.value
+	}
+	invalidations := c.invalidations
+	signalMu.Unlock()
+
+	var value interface{}
+	trackDependencies(c, func() {
+		value = c.compute()
+	})
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	if c.invalidations == invalidations {
+		c.value, c.valid = value, true
+	}
+	return value
+}
+
+// invalidate implements the dependent interface.
+func (c *Computed) invalidate(rerender *[]Component) (keep bool) {
+	c.invalidations++
+	if c.valid {
+		c.valid = false
+		c.dependents.invalidate(rerender)
+	}
+	// Our dependencies are tracked again the next time we are computed.
+	return false
+}
+
+// dependent is something which depends on a Signal or Computed, i.e. a
+// Computed or a Component.
+type dependent interface {
+	// invalidate is called, with signalMu held, when the value depended upon
+	// has changed. Components to re-render are appended to rerender. If it
+	// returns false, the dependency is removed.
+	invalidate(rerender *[]Component) (keep bool)
+}
+
+// componentDependent is a Component which depends on a Signal or Computed.
+type componentDependent struct {
+	c Component
+}
+
+// invalidate implements the dependent interface.
+func (d componentDependent) invalidate(rerender *[]Component) (keep bool) {
+	coreMu.RLock()
+	unmounted := d.c.Context().unmounted
+	coreMu.RUnlock()
+	if unmounted {
+		return false
+	}
+	*rerender = append(*rerender, d.c)
+	return true
+}
+
+// rerenderDependents re-renders the given components, which depend on a
+// changed Signal or Computed, unless they are yet to be rendered. It is called
+// without signalMu held, as a synchronous Scheduler may render them (and thus
+// read Signals) before Rerender returns.
+func rerenderDependents(components []Component) {
+	for _, c := range components {
+		coreMu.RLock()
+		rendered := c.Context().prevRender != nil
+		coreMu.RUnlock()
+		if rendered {
+			Rerender(c)
+		}
+	}
+}
+
+// signalMu guards the values of every Signal and Computed, their dependents,
+// the dependencies of components, and tracking. It is never held whilst
+// calling Render, the function of a Computed, or Rerender.
+var signalMu sync.Mutex
+
+// tracking is the stack of dependents currently being rendered or computed;
+// the last one becomes dependent on any Signal or Computed read.
+var tracking []dependent
+
+// trackDependencies invokes fn, making d dependent on every Signal or
+// Computed read during it.
+func trackDependencies(d dependent, fn func()) {
+	signalMu.Lock()
+	tracking = append(tracking, d)
+	signalMu.Unlock()
+	defer func() {
+		signalMu.Lock()
+		defer signalMu.Unlock()
+		// A Computed may be computed by another goroutine meanwhile, so d is
+		// not necessarily the last one.
+		for i := len(tracking) - 1; i >= 0; i-- {
+			if tracking[i] == d {
+				tracking = append(tracking[:i], tracking[i+1:]...)
+				break
+			}
+		}
+	}()
+	fn()
+}
+
+// untrackDependencies removes the dependencies of the Component recorded
+// during its last Render, so that only the Signals and Computeds read by its
+// next Render re-render it.
+func untrackDependencies(c Component) {
+	signalMu.Lock()
+	defer signalMu.Unlock()
+	ctx := c.Context()
+	for _, d := range ctx.dependencies {
+		delete(*d, componentDependent{c})
+	}
+	ctx.dependencies = nil
+}
+
+// dependents is a set of dependents of a Signal or Computed.
+type dependents map[dependent]struct{}
+
+// track adds the dependent currently being rendered or computed, if any. It is
+// called with signalMu held.
+func (d *dependents) track() {
+	if len(tracking) == 0 {
+		return
+	}
+	dep := tracking[len(tracking)-1]
+	if _, ok := (*d)[dep]; ok {
+		return
+	}
+	if *d == nil {
+		*d = make(dependents)
+	}
+	(*d)[dep] = struct{}{}
+	if cd, ok := dep.(componentDependent); ok {
+		ctx := cd.c.Context()
+		ctx.dependencies = append(ctx.dependencies, d)
+	}
+}
+
+// invalidate invalidates all dependents, appending the components to re-render
+// to rerender. It is called with signalMu held.
+func (d dependents) invalidate(rerender *[]Component) {
+	for dep := range d {
+		if !dep.invalidate(rerender) {
+			delete(d, dep)
+		}
+	}
+}
@@ -0,0 +1,216 @@
+package vecty
+
+import (
+	"sync"
+	"testing"
+)
+
+// TestSignal_Rerender tests that components reading a Signal during Render are
+// re-rendered when it changes, and only then.
+func TestSignal_Rerender(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	title := NewSignal("a")
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			return Tag("body", Text(title.Get().(string)))
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	if renderCalled != 1 {
+		t.Fatal("renderCalled != 1")
+	}
+
+	// Setting an identical value must not re-render.
+	title.Set("a")
+	if len(comp.Context().root.batch.batch) != 0 {
+		t.Fatal("len(batch.batch) != 0")
+	}
+
+	ts.record("(expect text to change now)")
+	title.Set("b")
+	ts.invokeCallbackRequestAnimationFrame(0)
+	if renderCalled != 2 {
+		t.Fatal("renderCalled != 2")
+	}
+
+	// Once unmounted, the component must not be re-rendered and its dependency
+	// must be removed.
+	unmount(comp)
+	title.Set("c")
+	if len(comp.Context().root.batch.batch) != 0 {
+		t.Fatal("len(batch.batch) != 0")
+	}
+	if len(title.dependents) != 0 {
+		t.Fatal("len(title.dependents) != 0")
+	}
+}
+
+// TestSignal_Rerender_untrack tests that components no longer depend on the
+// Signals they stop reading.
+func TestSignal_Rerender_untrack(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	ts.ints.mock(`global.Call("requestAnimationFrame", func)`, 0)
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	show, title := NewSignal(true), NewSignal("a")
+	var renderCalled int
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			renderCalled++
+			if show.Get().(bool) {
+				return Tag("body", Text(title.Get().(string)))
+			}
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	if len(title.dependents) != 1 {
+		t.Fatal("len(title.dependents) != 1")
+	}
+
+	ts.record("(expect text to be removed now)")
+	show.Set(false)
+	ts.invokeCallbackRequestAnimationFrame(0)
+	if renderCalled != 2 {
+		t.Fatal("renderCalled != 2")
+	}
+	if len(title.dependents) != 0 {
+		t.Fatal("len(title.dependents) != 0")
+	}
+
+	// The component no longer reads title, so must not be re-rendered.
+	title.Set("b")
+	if len(comp.Context().root.batch.batch) != 0 {
+		t.Fatal("len(batch.batch) != 0")
+	}
+	if len(show.dependents) != 1 {
+		t.Fatal("len(show.dependents) != 1")
+	}
+}
+
+// TestComputed tests that a Computed is only recomputed when the Signals it
+// depends on change.
+func TestComputed(t *testing.T) {
+	a, b := NewSignal(1), NewSignal(2)
+	var computeCalled int
+	sum := NewComputed(func() interface{} {
+		computeCalled++
+		return a.Get().(int) + b.Get().(int)
+	})
+	double := NewComputed(func() interface{} {
+		return sum.Get().(int) * 2
+	})
+
+	if got := double.Get(); got != 6 {
+		t.Fatalf("got %v want 6", got)
+	}
+	if got := double.Get(); got != 6 {
+		t.Fatalf("got %v want 6", got)
+	}
+	if computeCalled != 1 {
+		t.Fatalf("computeCalled %d want 1", computeCalled)
+	}
+
+	b.Set(5)
+	if got := double.Get(); got != 12 {
+		t.Fatalf("got %v want 12", got)
+	}
+	if computeCalled != 2 {
+		t.Fatalf("computeCalled %d want 2", computeCalled)
+	}
+
+	// Reading a Signal outside of a Render or computation must not track it.
+	a.Peek()
+	a.Get()
+	if len(a.dependents) != 1 {
+		t.Fatalf("len(a.dependents) %d want 1", len(a.dependents))
+	}
+}
+
+// TestSignal_Set_uncomparable tests that Set does not panic for values of
+// uncomparable types, and treats them as changed.
+func TestSignal_Set_uncomparable(t *testing.T) {
+	items := []string{"a"}
+	s := NewSignal(items)
+	var computeCalled int
+	c := NewComputed(func() interface{} {
+		computeCalled++
+		return len(s.Get().([]string))
+	})
+	c.Get()
+
+	items = append(items, "b")
+	s.Set(items)
+	if got := c.Get(); got != 2 {
+		t.Fatalf("got %v want 2", got)
+	}
+	if computeCalled != 2 {
+		t.Fatalf("computeCalled %d want 2", computeCalled)
+	}
+}
+
+// TestSignal_Set_goroutines tests that Signals may be Set from goroutines
+// whilst the components depending on them are rendered, which the race
+// detector checks.
+func TestSignal_Set_goroutines(t *testing.T) {
+	ts := testSuite(t)
+	defer ts.done()
+
+	// Frames are scheduled from the goroutines, so must not call into JS.
+	SetScheduler(&ManualScheduler{})
+	defer SetScheduler(AnimationFrameScheduler())
+	ts.strings.mock(`global.Get("document").Get("readyState")`, "complete")
+	ts.strings.mock(`global.Get("document").Call("querySelector", "body").Get("nodeName")`, "BODY")
+	ts.truthies.mock(`global.Get("document").Call("querySelector", "body")`, true)
+
+	count := NewSignal(0)
+	double := NewComputed(func() interface{} {
+		return count.Get().(int) * 2
+	})
+	comp := &componentFunc{
+		render: func() ComponentOrHTML {
+			_ = double.Get()
+			return Tag("body")
+		},
+		skipRender: func(prev Component) bool { return false },
+	}
+	RenderBody(comp)
+	ts.record("(expect body to be set now)")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				count.Set(i*100 + j + 1)
+				_ = count.Peek()
+				_ = double.Get()
+			}
+		}(i)
+	}
+	for i := 0; i < 10; i++ {
+		RerenderSync(comp)
+	}
+	wg.Wait()
+	ts.record("(rendered concurrently)")
+
+	if got, want := double.Get().(int), count.Peek().(int)*2; got != want {
+		t.Fatalf("got %d want %d once settled", got, want)
+	}
+}
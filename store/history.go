@@ -0,0 +1,118 @@
+package store
+
+import "github.com/hexops/vecty/internal/identical"
+
+// History records the actions dispatched to a Store along with the states
+// preceding them, such that their changes can be undone and redone.
+//
+// Undo and Redo replace the state of the Store and notify its subscribers
+// exactly as a Dispatch would, so components subscribed via Select are
+// re-rendered (once per frame, as vecty.Rerender batches renders) only if
+// their selected slice of the state changed.
+type History struct {
+	store        *Store
+	limit        int
+	past, future []historyEntry
+}
+
+// historyEntry is a dispatched action along with the state it was applied
+// to (for past entries) or resulted in (for future entries).
+type historyEntry struct {
+	action Action
+	state  interface{}
+}
+
+// NewHistory returns a new History recording the actions dispatched to the
+// given Store from now on. At most limit actions are recorded; if limit is
+// zero, the number of recorded actions is unbounded.
+//
+// Actions which leave the state unmodified are not recorded.
+func NewHistory(s *Store, limit int) *History {
+	h := &History{store: s, limit: limit}
+	s.Use(h.middleware)
+	return h
+}
+
+// middleware is the Middleware through which the History records actions.
+func (h *History) middleware(s *Store, next Dispatcher) Dispatcher {
+	return func(action Action) {
+		prev := s.state
+		next(action)
+		if identical.Values(prev, s.state) {
+			return
+		}
+		h.past = append(h.past, historyEntry{action: action, state: prev})
+		if h.limit > 0 && len(h.past) > h.limit {
+			h.past = h.past[len(h.past)-h.limit:]
+		}
+		h.future = nil
+	}
+}
+
+// CanUndo reports whether there is an action to undo.
+func (h *History) CanUndo() bool {
+	return len(h.past) > 0
+}
+
+// CanRedo reports whether there is an undone action to redo.
+func (h *History) CanRedo() bool {
+	return len(h.future) > 0
+}
+
+// Undo reverts the state of the Store to the one preceding the most recently
+// recorded action. It is no-op if there is nothing to undo.
+//
+// Like Dispatch, Undo panics if called during a Dispatch.
+func (h *History) Undo() {
+	if h.store.dispatching {
+		panic("store: Undo illegally called during Dispatch")
+	}
+	if !h.CanUndo() {
+		return
+	}
+	entry := h.past[len(h.past)-1]
+	h.past = h.past[:len(h.past)-1]
+	h.future = append(h.future, historyEntry{action: entry.action, state: h.store.state})
+	h.restore(entry.state)
+}
+
+// Redo restores the state of the Store resulting from the most recently
+// undone action. It is no-op if there is nothing to redo.
+//
+// Like Dispatch, Redo panics if called during a Dispatch.
+func (h *History) Redo() {
+	if h.store.dispatching {
+		panic("store: Redo illegally called during Dispatch")
+	}
+	if !h.CanRedo() {
+		return
+	}
+	entry := h.future[len(h.future)-1]
+	h.future = h.future[:len(h.future)-1]
+	h.past = append(h.past, historyEntry{action: entry.action, state: h.store.state})
+	h.restore(entry.state)
+}
+
+// Actions returns the recorded actions which may be undone, oldest first.
+func (h *History) Actions() []Action {
+	actions := make([]Action, len(h.past))
+	for i, entry := range h.past {
+		actions[i] = entry.action
+	}
+	return actions
+}
+
+// Clear forgets all recorded actions.
+func (h *History) Clear() {
+	h.past = nil
+	h.future = nil
+}
+
+// restore replaces the state of the Store, notifying its subscribers.
+func (h *History) restore(state interface{}) {
+	s := h.store
+	s.dispatching = true
+	defer func() { s.dispatching = false }()
+
+	s.setState(state)
+}
@@ -0,0 +1,66 @@
+package store
+
+import "testing"
+
+func TestHistory(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	h := NewHistory(s, 0)
+	var notified int
+	s.Subscribe(func() { notified++ })
+
+	count := func() int { return s.State().(*counterState).Count }
+
+	if h.CanUndo() || h.CanRedo() {
+		t.Fatal("expected nothing to undo or redo")
+	}
+	s.Dispatch(&increment{})
+	s.Dispatch(&increment{})
+	s.Dispatch(&struct{}{}) // unknown action, not recorded.
+	if got := len(h.Actions()); got != 2 {
+		t.Fatalf("got %d recorded actions want 2", got)
+	}
+
+	h.Undo()
+	h.Undo()
+	h.Undo() // no-op, nothing left to undo.
+	if got := count(); got != 0 {
+		t.Fatalf("got count %d after Undo want 0", got)
+	}
+	if h.CanUndo() || !h.CanRedo() {
+		t.Fatal("expected only redo to be possible")
+	}
+
+	h.Redo()
+	if got := count(); got != 1 {
+		t.Fatalf("got count %d after Redo want 1", got)
+	}
+
+	// Dispatching discards undone actions.
+	s.Dispatch(&setLabel{Label: "a"})
+	if h.CanRedo() {
+		t.Fatal("expected nothing to redo after Dispatch")
+	}
+	h.Undo()
+	if got := s.State().(*counterState); got.Count != 1 || got.Label != "" {
+		t.Fatalf("got state %+v after Undo want count 1 and no label", got)
+	}
+
+	// 4 dispatches, 3 undos and 1 redo.
+	if notified != 8 {
+		t.Fatalf("got %d notifications want 8", notified)
+	}
+}
+
+func TestHistory_limit(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	h := NewHistory(s, 2)
+	for i := 0; i < 5; i++ {
+		s.Dispatch(&increment{})
+	}
+	for h.CanUndo() {
+		h.Undo()
+	}
+	if got := s.State().(*counterState).Count; got != 3 {
+		t.Fatalf("got count %d want 3", got)
+	}
+}
@@ -0,0 +1,192 @@
+// Package persist persists the state of a store.Store, so that it survives
+// reloads of the application.
+//
+// State is serialized as JSON (via encoding/json) into a Storage, such as the
+// browser's localStorage or IndexedDB, every time it changes. At startup,
+// before rendering, it is restored and migrated from the version it was saved
+// at to the current version:
+//
+// 	p := &persist.Persister{
+// 		Storage: persist.LocalStorage(),
+// 		Key:     "state",
+// 		Version: 2,
+// 		Migrations: map[int]persist.Migration{
+// 			1: migrateV1ToV2,
+// 		},
+// 	}
+// 	state := &State{}
+// 	if _, err := p.Load(state); err != nil {
+// 		println("failed to restore state: " + err.Error())
+// 	}
+// 	s := store.New(state, reduce)
+// 	p.Attach(s)
+// 	vecty.RenderBody(&PageView{})
+package persist
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hexops/vecty/internal/identical"
+	"github.com/hexops/vecty/store"
+)
+
+// Storage is a key-value storage which persisted state is saved into.
+type Storage interface {
+	// Load returns the data saved under the given key. If there is none, ok
+	// is false.
+	Load(key string) (data []byte, ok bool, err error)
+
+	// Save saves the data under the given key.
+	Save(key string, data []byte) error
+}
+
+// AsyncStorage is a Storage which saves asynchronously, such that errors may
+// occur after Save has returned. Persister reports them via its OnError.
+type AsyncStorage interface {
+	Storage
+
+	// SetErrorHandler sets the function invoked with errors which occur whilst
+	// saving, after Save has returned.
+	SetErrorHandler(handler func(err error))
+}
+
+// MemoryStorage is a Storage which keeps data in memory. It is useful for
+// tests and for non-browser environments.
+type MemoryStorage map[string][]byte
+
+// Load implements the Storage interface.
+func (m MemoryStorage) Load(key string) (data []byte, ok bool, err error) {
+	data, ok = m[key]
+	return data, ok, nil
+}
+
+// Save implements the Storage interface.
+func (m MemoryStorage) Save(key string, data []byte) error {
+	m[key] = append([]byte(nil), data...)
+	return nil
+}
+
+// Migration migrates JSON-encoded state saved at one version to the next
+// version.
+type Migration func(state json.RawMessage) (json.RawMessage, error)
+
+// Persister saves state into a Storage, and restores it from there.
+type Persister struct {
+	// Storage is where state is saved.
+	Storage Storage
+
+	// Key is the key under which state is saved in the Storage.
+	Key string
+
+	// Version is the current version of the state. It should be incremented,
+	// alongside adding a Migration, whenever the state is changed in a way
+	// which previously saved state cannot be decoded into.
+	Version int
+
+	// Migrations maps versions to the Migration from that version to the
+	// next one. State saved at an older version than Version is migrated one
+	// version at a time before being decoded.
+	//
+	// State which was saved without a version (e.g. by hand, before using
+	// this package) is considered to be at version zero.
+	Migrations map[int]Migration
+
+	// Select optionally selects the slice of the state of the Store that
+	// Attach saves. If nil, the entire state is saved.
+	Select store.Selector
+
+	// OnError is invoked with errors that occur when Attach saves the state,
+	// including those an AsyncStorage reports after saving. If nil, they are
+	// printed.
+	OnError func(err error)
+}
+
+// envelope is the format in which state is saved.
+type envelope struct {
+	Version *int            `json:"version"`
+	State   json.RawMessage `json:"state"`
+}
+
+// Save saves the given state, encoded as JSON, at the current Version.
+func (p *Persister) Save(state interface{}) error {
+	encoded, err := json.Marshal(state)
+	if err != nil {
+		return fmt.Errorf("persist: encoding state: %v", err)
+	}
+	version := p.Version
+	data, err := json.Marshal(envelope{Version: &version, State: encoded})
+	if err != nil {
+		return fmt.Errorf("persist: encoding state: %v", err)
+	}
+	if a, ok := p.Storage.(AsyncStorage); ok {
+		a.SetErrorHandler(p.reportError)
+	}
+	return p.Storage.Save(p.Key, data)
+}
+
+// Load restores the saved state into v, which must be a pointer as per
+// json.Unmarshal. The saved state is first migrated to the current Version.
+//
+// If no state has been saved, v is left untouched and ok is false.
+func (p *Persister) Load(v interface{}) (ok bool, err error) {
+	data, ok, err := p.Storage.Load(p.Key)
+	if err != nil || !ok {
+		return false, err
+	}
+	version, state := 0, json.RawMessage(data)
+	var env envelope
+	if json.Unmarshal(data, &env) == nil && env.Version != nil {
+		version, state = *env.Version, env.State
+	}
+	if version > p.Version {
+		return false, fmt.Errorf("persist: saved state version %d is newer than current version %d", version, p.Version)
+	}
+	for ; version < p.Version; version++ {
+		migrate, ok := p.Migrations[version]
+		if !ok {
+			return false, fmt.Errorf("persist: no migration from version %d", version)
+		}
+		state, err = migrate(state)
+		if err != nil {
+			return false, fmt.Errorf("persist: migrating from version %d: %v", version, err)
+		}
+	}
+	if err := json.Unmarshal(state, v); err != nil {
+		return false, fmt.Errorf("persist: decoding state: %v", err)
+	}
+	return true, nil
+}
+
+// Attach saves the state of the given Store (or the slice chosen by Select)
+// whenever it changes. The returned Subscription may be used to stop saving.
+func (p *Persister) Attach(s *store.Store) *store.Subscription {
+	last := p.selected(s.State())
+	return s.Subscribe(func() {
+		state := p.selected(s.State())
+		if identical.Values(state, last) {
+			return
+		}
+		last = state
+		if err := p.Save(state); err != nil {
+			p.reportError(err)
+		}
+	})
+}
+
+// reportError reports an error which occurred whilst saving via OnError.
+func (p *Persister) reportError(err error) {
+	if p.OnError == nil {
+		println(err.Error())
+		return
+	}
+	p.OnError(err)
+}
+
+// selected returns the slice of state to save.
+func (p *Persister) selected(state interface{}) interface{} {
+	if p.Select == nil {
+		return state
+	}
+	return p.Select(state)
+}
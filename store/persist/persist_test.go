@@ -0,0 +1,175 @@
+package persist
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hexops/vecty/store"
+)
+
+type state struct {
+	Items []string `json:"items"`
+}
+
+type addItem struct{ Item string }
+
+func reduce(s interface{}, action store.Action) interface{} {
+	if a, ok := action.(*addItem); ok {
+		items := append(append([]string(nil), s.(*state).Items...), a.Item)
+		return &state{Items: items}
+	}
+	return s
+}
+
+func TestPersister_SaveLoad(t *testing.T) {
+	p := &Persister{Storage: MemoryStorage{}, Key: "state", Version: 3}
+
+	var got state
+	ok, err := p.Load(&got)
+	if ok || err != nil {
+		t.Fatalf("got ok %v err %v loading nothing, want false, nil", ok, err)
+	}
+
+	want := state{Items: []string{"a", "b"}}
+	if err := p.Save(&want); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := p.Load(&got); !ok || err != nil {
+		t.Fatalf("got ok %v err %v, want true, nil", ok, err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %+v want %+v", got, want)
+	}
+}
+
+func TestPersister_Load_migrations(t *testing.T) {
+	storage := MemoryStorage{
+		// Saved without a version, i.e. version zero.
+		"v0": []byte(`["a","b"]`),
+		"v1": []byte(`{"version":1,"state":{"list":["c"]}}`),
+		"v3": []byte(`{"version":3,"state":{"items":["d"]}}`),
+	}
+	p := &Persister{
+		Storage: storage,
+		Version: 2,
+		Migrations: map[int]Migration{
+			// Version 0 saved the items alone.
+			0: func(s json.RawMessage) (json.RawMessage, error) {
+				return json.Marshal(map[string]json.RawMessage{"list": s})
+			},
+			// Version 1 named the items "list".
+			1: func(s json.RawMessage) (json.RawMessage, error) {
+				var v1 struct{ List []string }
+				if err := json.Unmarshal(s, &v1); err != nil {
+					return nil, err
+				}
+				return json.Marshal(state{Items: v1.List})
+			},
+		},
+	}
+	cases := []struct {
+		key     string
+		want    state
+		wantErr string
+	}{
+		{key: "v0", want: state{Items: []string{"a", "b"}}},
+		{key: "v1", want: state{Items: []string{"c"}}},
+		{key: "v3", wantErr: "persist: saved state version 3 is newer than current version 2"},
+	}
+	for _, tst := range cases {
+		t.Run(tst.key, func(t *testing.T) {
+			p.Key = tst.key
+			var got state
+			_, err := p.Load(&got)
+			if tst.wantErr != "" {
+				if err == nil || err.Error() != tst.wantErr {
+					t.Fatalf("got error %v want %q", err, tst.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tst.want) {
+				t.Fatalf("got %+v want %+v", got, tst.want)
+			}
+		})
+	}
+
+	p.Key = "v0"
+	delete(p.Migrations, 1)
+	if _, err := p.Load(&state{}); err == nil || !strings.Contains(err.Error(), "no migration from version 1") {
+		t.Fatalf("got error %v want missing migration", err)
+	}
+}
+
+type failingStorage struct{ MemoryStorage }
+
+func (failingStorage) Save(key string, data []byte) error {
+	return errors.New("quota exceeded")
+}
+
+func TestPersister_Attach(t *testing.T) {
+	storage := MemoryStorage{}
+	p := &Persister{
+		Storage: storage,
+		Key:     "items",
+		Select: func(s interface{}) interface{} {
+			return s.(*state).Items
+		},
+	}
+	s := store.New(&state{}, reduce)
+	sub := p.Attach(s)
+	s.Dispatch(&addItem{Item: "a"})
+
+	var got []string
+	if _, err := p.Load(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v want %v", got, want)
+	}
+
+	sub.Unsubscribe()
+	var gotErr error
+	p.Storage = failingStorage{storage}
+	p.OnError = func(err error) { gotErr = err }
+	p.Attach(s)
+	s.Dispatch(&addItem{Item: "b"})
+	if gotErr == nil || gotErr.Error() != "quota exceeded" {
+		t.Fatalf("got error %v want quota exceeded", gotErr)
+	}
+}
+
+// asyncStorage is an AsyncStorage which fails after Save returns.
+type asyncStorage struct {
+	MemoryStorage
+	onError func(err error)
+}
+
+func (a *asyncStorage) Save(key string, data []byte) error {
+	a.onError(errors.New("write failed"))
+	return nil
+}
+
+func (a *asyncStorage) SetErrorHandler(handler func(err error)) {
+	a.onError = handler
+}
+
+func TestPersister_Save_async(t *testing.T) {
+	var gotErr error
+	p := &Persister{
+		Storage: &asyncStorage{MemoryStorage: MemoryStorage{}},
+		Key:     "items",
+		OnError: func(err error) { gotErr = err },
+	}
+	if err := p.Save(&state{}); err != nil {
+		t.Fatal(err)
+	}
+	if gotErr == nil || gotErr.Error() != "write failed" {
+		t.Fatalf("got error %v want write failed", gotErr)
+	}
+}
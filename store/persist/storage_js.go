@@ -0,0 +1,244 @@
+// +build js
+
+package persist
+
+import (
+	"errors"
+	"sync"
+	"syscall/js"
+)
+
+// localStorage is a Storage backed by the browser's localStorage.
+type localStorage struct{}
+
+// LocalStorage returns a Storage backed by the browser's localStorage, which
+// is synchronous and suitable for small amounts of state.
+func LocalStorage() Storage {
+	return localStorage{}
+}
+
+// Load implements the Storage interface.
+func (localStorage) Load(key string) (data []byte, ok bool, err error) {
+	defer recoverJSError(&err)
+	item := js.Global().Get("localStorage").Call("getItem", key)
+	if item.IsNull() {
+		return nil, false, nil
+	}
+	return []byte(item.String()), true, nil
+}
+
+// Save implements the Storage interface.
+func (localStorage) Save(key string, data []byte) (err error) {
+	defer recoverJSError(&err)
+	js.Global().Get("localStorage").Call("setItem", key, string(data))
+	return nil
+}
+
+// indexedDB is a Storage backed by an IndexedDB object store.
+type indexedDB struct {
+	database, objectStore string
+	ready                 chan struct{}
+	db                    js.Value
+	err                   error
+
+	mu sync.Mutex
+	// pending maps keys to the data last saved under them, which is yet to be
+	// written.
+	pending map[string][]byte
+	// writing is whether the writer goroutine is running.
+	writing bool
+	onError func(err error)
+}
+
+// IndexedDB returns a Storage backed by an object store of the given
+// IndexedDB database, both of which are created if they do not exist. It is
+// suitable for larger amounts of state than LocalStorage.
+//
+// As IndexedDB is asynchronous, Load blocks until the data has been loaded,
+// and must not be called from within an event listener; it is meant to be
+// called at startup, before rendering. Save does not block: data is written
+// in the background one key at a time, such that the data last saved under a
+// key is what it holds once written, and data superseded before being written
+// is skipped. The returned Storage is an AsyncStorage, whose errors which
+// occur whilst writing are printed unless handled.
+func IndexedDB(database, objectStore string) Storage {
+	s := &indexedDB{
+		database:    database,
+		objectStore: objectStore,
+		ready:       make(chan struct{}),
+		pending:     make(map[string][]byte),
+	}
+	s.open(0)
+	return s
+}
+
+// open opens the database at the given version, or its current version if
+// zero, creating the object store if it does not exist.
+func (s *indexedDB) open(version int) {
+	args := []interface{}{s.database}
+	if version > 0 {
+		args = append(args, version)
+	}
+	req := js.Global().Get("indexedDB").Call("open", args...)
+	listen(req, map[string]func(js.Value){
+		"upgradeneeded": func(js.Value) {
+			db := req.Get("result")
+			if !db.Get("objectStoreNames").Call("contains", s.objectStore).Bool() {
+				db.Call("createObjectStore", s.objectStore)
+			}
+		},
+		"success": func(js.Value) {
+			db := req.Get("result")
+			if !db.Get("objectStoreNames").Call("contains", s.objectStore).Bool() {
+				// The database exists without the object store, which may only
+				// be created by upgrading it to a new version.
+				db.Call("close")
+				s.open(db.Get("version").Int() + 1)
+				return
+			}
+			s.db = db
+			close(s.ready)
+		},
+		"error": func(js.Value) {
+			s.err = errors.New("persist: opening IndexedDB database: " + req.Get("error").Call("toString").String())
+			close(s.ready)
+		},
+	})
+}
+
+// Load implements the Storage interface.
+func (s *indexedDB) Load(key string) (data []byte, ok bool, err error) {
+	<-s.ready
+	if s.err != nil {
+		return nil, false, s.err
+	}
+	defer recoverJSError(&err)
+	type result struct {
+		data []byte
+		ok   bool
+		err  error
+	}
+	done := make(chan result, 1)
+	req := s.db.Call("transaction", s.objectStore, "readonly").Call("objectStore", s.objectStore).Call("get", key)
+	listen(req, map[string]func(js.Value){
+		"success": func(js.Value) {
+			value := req.Get("result")
+			if value.IsUndefined() {
+				done <- result{}
+				return
+			}
+			done <- result{data: []byte(value.String()), ok: true}
+		},
+		"error": func(js.Value) {
+			done <- result{err: errors.New("persist: loading from IndexedDB: " + req.Get("error").Call("toString").String())}
+		},
+	})
+	r := <-done
+	return r.data, r.ok, r.err
+}
+
+// Save implements the Storage interface.
+func (s *indexedDB) Save(key string, data []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pending[key] = append([]byte(nil), data...)
+	if !s.writing {
+		s.writing = true
+		go s.write()
+	}
+	return nil
+}
+
+// SetErrorHandler implements the AsyncStorage interface.
+func (s *indexedDB) SetErrorHandler(handler func(err error)) {
+	s.mu.Lock()
+	s.onError = handler
+	s.mu.Unlock()
+}
+
+// write writes the pending data one key at a time, until there is none.
+func (s *indexedDB) write() {
+	<-s.ready
+	for {
+		s.mu.Lock()
+		var (
+			key   string
+			data  []byte
+			found bool
+		)
+		for key, data = range s.pending {
+			found = true
+			break
+		}
+		if !found {
+			s.writing = false
+			s.mu.Unlock()
+			return
+		}
+		delete(s.pending, key)
+		onError := s.onError
+		s.mu.Unlock()
+
+		err := s.err
+		if err == nil {
+			err = s.put(key, data)
+		}
+		if err != nil {
+			if onError == nil {
+				println(err.Error())
+				continue
+			}
+			onError(err)
+		}
+	}
+}
+
+// put writes the data under the given key, waiting until it is written.
+func (s *indexedDB) put(key string, data []byte) (err error) {
+	defer recoverJSError(&err)
+	done := make(chan error, 1)
+	req := s.db.Call("transaction", s.objectStore, "readwrite").Call("objectStore", s.objectStore).Call("put", string(data), key)
+	listen(req, map[string]func(js.Value){
+		"success": func(js.Value) {
+			done <- nil
+		},
+		"error": func(js.Value) {
+			done <- errors.New("persist: saving to IndexedDB: " + req.Get("error").Call("toString").String())
+		},
+	})
+	return <-done
+}
+
+// listen registers the given event handlers on an IndexedDB request, which
+// are released once the request has either succeeded or failed.
+func listen(req js.Value, handlers map[string]func(event js.Value)) {
+	var funcs []js.Func
+	for name, handler := range handlers {
+		name, handler := name, handler
+		f := js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+			if name == "success" || name == "error" {
+				for _, f := range funcs {
+					f.Release()
+				}
+			}
+			handler(args[0])
+			return nil
+		})
+		funcs = append(funcs, f)
+		req.Set("on"+name, f)
+	}
+}
+
+// recoverJSError recovers from a panic caused by a JavaScript exception (e.g.
+// when storage is disabled or full), storing it into err.
+func recoverJSError(err *error) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	jsErr, ok := r.(js.Error)
+	if !ok {
+		panic(r)
+	}
+	*err = errors.New("persist: " + jsErr.Error())
+}
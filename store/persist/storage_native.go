@@ -0,0 +1,32 @@
+// +build !js
+
+package persist
+
+// LocalStorage returns a Storage backed by the browser's localStorage, which
+// is synchronous and suitable for small amounts of state.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics. Use MemoryStorage instead.
+func LocalStorage() Storage {
+	panic("persist: LocalStorage is only supported when running inside a browser")
+}
+
+// IndexedDB returns a Storage backed by an object store of the given
+// IndexedDB database, both of which are created if they do not exist. It is
+// suitable for larger amounts of state than LocalStorage.
+//
+// As IndexedDB is asynchronous, Load blocks until the data has been loaded,
+// and must not be called from within an event listener; it is meant to be
+// called at startup, before rendering. Save does not block: data is written
+// in the background one key at a time, such that the data last saved under a
+// key is what it holds once written, and data superseded before being written
+// is skipped. The returned Storage is an AsyncStorage, whose errors which
+// occur whilst writing are printed unless handled.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics. Use MemoryStorage instead.
+func IndexedDB(database, objectStore string) Storage {
+	panic("persist: IndexedDB is only supported when running inside a browser")
+}
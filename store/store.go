@@ -0,0 +1,227 @@
+// Package store provides a state container for Vecty applications.
+//
+// A Store holds the state of an application, which changes only through
+// actions dispatched to it. Each action is applied to the state by a Reducer:
+//
+// 	type State struct {
+// 		Items []string
+// 	}
+//
+// 	// AddItem is an action which adds an item.
+// 	type AddItem struct{ Item string }
+//
+// 	func reduce(state interface{}, action store.Action) interface{} {
+// 		s := state.(*State)
+// 		switch a := action.(type) {
+// 		case *AddItem:
+// 			items := append(append([]string(nil), s.Items...), a.Item)
+// 			return &State{Items: items}
+// 		}
+// 		return s
+// 	}
+//
+// 	var Store = store.New(&State{}, reduce)
+//
+// Components subscribe to the slice of the state they render via Select, and
+// are re-rendered only when that slice changes. By embedding Subscriptions,
+// their subscriptions are released automatically when they are unmounted:
+//
+// 	type ItemCount struct {
+// 		vecty.Core
+// 		store.Subscriptions
+// 	}
+//
+// 	func (c *ItemCount) Mount() {
+// 		Store.Select(c, func(state interface{}) interface{} {
+// 			return len(state.(*State).Items)
+// 		})
+// 	}
+package store
+
+import (
+	"reflect"
+
+	"github.com/hexops/vecty"
+)
+
+// Action describes a change to the state of a Store. Actions are typically
+// pointers to a struct type per kind of change, which reducers distinguish
+// using a type switch.
+type Action interface{}
+
+// Reducer returns the state resulting from applying the action to the given
+// state. Actions which do not concern the reducer should return the state
+// unmodified.
+//
+// Reducers must not modify the given state in-place, but return a new state
+// instead. Otherwise, selectors cannot tell what has changed.
+type Reducer func(state interface{}, action Action) interface{}
+
+// Selector selects the slice of the state which a subscriber depends on.
+type Selector func(state interface{}) interface{}
+
+// Dispatcher applies an action to the state of a Store.
+type Dispatcher func(action Action)
+
+// Middleware wraps the application of actions to a Store, e.g. to record or
+// transform them. It returns a Dispatcher which is expected to invoke next
+// in order to apply the action.
+type Middleware func(s *Store, next Dispatcher) Dispatcher
+
+// Store holds the state of an application.
+type Store struct {
+	state       interface{}
+	reducer     Reducer
+	dispatch    Dispatcher
+	subs        []*Subscription
+	dispatching bool
+}
+
+// New returns a new Store with the given initial state, whose state is
+// changed by the given reducer.
+func New(initial interface{}, reducer Reducer) *Store {
+	s := &Store{
+		state:   initial,
+		reducer: reducer,
+	}
+	s.dispatch = s.reduce
+	return s
+}
+
+// Use adds the given middleware. Middleware added later wraps middleware
+// added earlier, and thus sees dispatched actions first.
+func (s *Store) Use(m Middleware) {
+	s.dispatch = m(s, s.dispatch)
+}
+
+// State returns the current state.
+func (s *Store) State() interface{} {
+	return s.state
+}
+
+// Dispatch applies the action to the current state using the Store's
+// reducer (through any middleware, see Use), and then notifies subscribers of
+// the change.
+//
+// Dispatch panics if it is called by a reducer or subscriber, i.e. during
+// another Dispatch.
+func (s *Store) Dispatch(action Action) {
+	if s.dispatching {
+		panic("store: Dispatch illegally called during Dispatch")
+	}
+	s.dispatching = true
+	defer func() { s.dispatching = false }()
+
+	s.dispatch(action)
+}
+
+// reduce applies the action to the current state using the Store's reducer,
+// and is the innermost Dispatcher.
+func (s *Store) reduce(action Action) {
+	s.setState(s.reducer(s.state, action))
+}
+
+// setState replaces the current state and notifies subscribers whose selected
+// slice of the state has changed.
+func (s *Store) setState(state interface{}) {
+	s.state = state
+	// Copy the subscriptions, as subscribers may unsubscribe during
+	// notification.
+	for _, sub := range append([]*Subscription(nil), s.subs...) {
+		sub.notify(state)
+	}
+}
+
+// Subscribe registers fn to be invoked after every dispatched action. The
+// returned Subscription may be used to unsubscribe.
+func (s *Store) Subscribe(fn func()) *Subscription {
+	return s.subscribe(nil, fn)
+}
+
+// Select subscribes the given component to the slice of the state chosen by
+// selector, such that the component is re-rendered via vecty.Rerender
+// whenever a dispatched action changes that slice. Slices are compared using
+// reflect.DeepEqual.
+//
+// Select should be called once the component has been rendered, typically in
+// its Mount method. If the component embeds Subscriptions, the returned
+// Subscription is unsubscribed automatically when the component is unmounted.
+func (s *Store) Select(c vecty.Component, selector Selector) *Subscription {
+	sub := s.subscribe(selector, func() {
+		vecty.Rerender(c)
+	})
+	if t, ok := c.(tracker); ok {
+		t.track(sub)
+	}
+	return sub
+}
+
+func (s *Store) subscribe(selector Selector, fn func()) *Subscription {
+	sub := &Subscription{store: s, selector: selector, fn: fn}
+	if selector != nil {
+		sub.selected = selector(s.state)
+	}
+	s.subs = append(s.subs, sub)
+	return sub
+}
+
+// Subscription is a handle to a subscription created through Subscribe or
+// Select.
+type Subscription struct {
+	store    *Store
+	selector Selector
+	selected interface{}
+	fn       func()
+}
+
+// notify invokes the subscriber, if the selected slice of the given state
+// differs from the last one the subscriber was notified of.
+func (s *Subscription) notify(state interface{}) {
+	if s.selector != nil {
+		selected := s.selector(state)
+		if reflect.DeepEqual(selected, s.selected) {
+			return
+		}
+		s.selected = selected
+	}
+	s.fn()
+}
+
+// Unsubscribe cancels the subscription. It is no-op if the subscription has
+// already been cancelled.
+func (s *Subscription) Unsubscribe() {
+	subs := s.store.subs
+	for i, sub := range subs {
+		if sub == s {
+			s.store.subs = append(subs[:i:i], subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// tracker is implemented by components embedding Subscriptions.
+type tracker interface {
+	track(sub *Subscription)
+}
+
+// Subscriptions tracks the subscriptions of a component made via
+// Store.Select, and unsubscribes them when the component is unmounted.
+//
+// It is meant to be embedded in components alongside vecty.Core, which makes
+// them implement the vecty.Unmounter interface. Components that implement
+// Unmount themselves must call Subscriptions.Unmount explicitly.
+type Subscriptions struct {
+	subs []*Subscription
+}
+
+func (s *Subscriptions) track(sub *Subscription) {
+	s.subs = append(s.subs, sub)
+}
+
+// Unmount implements the vecty.Unmounter interface.
+func (s *Subscriptions) Unmount() {
+	for _, sub := range s.subs {
+		sub.Unsubscribe()
+	}
+	s.subs = nil
+}
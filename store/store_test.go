@@ -0,0 +1,106 @@
+package store
+
+import (
+	"fmt"
+	"testing"
+)
+
+type counterState struct {
+	Count int
+	Label string
+}
+
+type increment struct{}
+
+type setLabel struct{ Label string }
+
+func reduceCounter(state interface{}, action Action) interface{} {
+	s := *state.(*counterState)
+	switch a := action.(type) {
+	case *increment:
+		s.Count++
+	case *setLabel:
+		s.Label = a.Label
+	default:
+		return state
+	}
+	return &s
+}
+
+func TestStore_Dispatch(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	var notified int
+	sub := s.Subscribe(func() { notified++ })
+
+	s.Dispatch(&increment{})
+	s.Dispatch(&increment{})
+	if got := s.State().(*counterState).Count; got != 2 {
+		t.Fatalf("got count %d want 2", got)
+	}
+	if notified != 2 {
+		t.Fatalf("got %d notifications want 2", notified)
+	}
+
+	sub.Unsubscribe()
+	sub.Unsubscribe() // no-op
+	s.Dispatch(&increment{})
+	if notified != 2 {
+		t.Fatalf("got %d notifications after Unsubscribe want 2", notified)
+	}
+}
+
+func TestStore_Dispatch_reentrant(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	s.Subscribe(func() { s.Dispatch(&increment{}) })
+
+	got := fmt.Sprint(func() (r interface{}) {
+		defer func() { r = recover() }()
+		s.Dispatch(&increment{})
+		return nil
+	}())
+	want := "store: Dispatch illegally called during Dispatch"
+	if got != want {
+		t.Fatalf("got panic %q want %q", got, want)
+	}
+}
+
+func TestStore_selector(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	var countChanges, labelChanges int
+	s.subscribe(func(state interface{}) interface{} {
+		return state.(*counterState).Count
+	}, func() { countChanges++ })
+	s.subscribe(func(state interface{}) interface{} {
+		return state.(*counterState).Label
+	}, func() { labelChanges++ })
+
+	s.Dispatch(&increment{})
+	s.Dispatch(&setLabel{Label: "a"})
+	s.Dispatch(&setLabel{Label: "a"})
+	s.Dispatch(&struct{}{}) // unknown action, state unchanged.
+
+	if countChanges != 1 {
+		t.Fatalf("got %d count changes want 1", countChanges)
+	}
+	if labelChanges != 1 {
+		t.Fatalf("got %d label changes want 1", labelChanges)
+	}
+}
+
+func TestSubscriptions_Unmount(t *testing.T) {
+	s := New(&counterState{}, reduceCounter)
+	var notified int
+	var subs Subscriptions
+	subs.track(s.Subscribe(func() { notified++ }))
+	subs.track(s.Subscribe(func() { notified++ }))
+
+	s.Dispatch(&increment{})
+	subs.Unmount()
+	s.Dispatch(&increment{})
+	if notified != 2 {
+		t.Fatalf("got %d notifications want 2", notified)
+	}
+	if len(s.subs) != 0 {
+		t.Fatalf("got %d remaining subscriptions want 0", len(s.subs))
+	}
+}
@@ -60,6 +60,9 @@ func testSuite(t *testing.T) *testSuiteT {
 		ts:   ts,
 		name: "global",
 	}
+	// Start each test without any roots or render statistics.
+	roots.list = nil
+	renderLoop.stats = RenderStats{}
 	return ts
 }
 
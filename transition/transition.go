@@ -0,0 +1,127 @@
+// Package transition animates elements as they enter and leave the page, e.g.
+// as items are added to and removed from a list:
+//
+// 	items := make(vecty.List, len(todos))
+// 	for i, todo := range todos {
+// 		items[i] = elem.ListItem(
+// 			vecty.Markup(vecty.Key(todo.ID), transition.Transition{Name: "fade"}),
+// 			vecty.Text(todo.Title),
+// 		)
+// 	}
+// 	return elem.UnorderedList(items)
+//
+// And the transition is defined in CSS:
+//
+// 	.fade-enter-active, .fade-leave-active { transition: opacity 0.3s; }
+// 	.fade-enter-from, .fade-leave-to { opacity: 0; }
+//
+// An element which leaves the page is unmounted immediately, but its DOM node
+// is only removed once its transition ends (see vecty.EnterLeave).
+package transition
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hexops/vecty"
+)
+
+// Keyframe is a keyframe of a Web Animation, mapping CSS properties (in
+// camelCase, e.g. "backgroundColor") to their values, and optionally
+// "offset" and "easing" to those of the keyframe.
+//
+// See https://developer.mozilla.org/en-US/docs/Web/API/Web_Animations_API/Keyframe_Formats.
+type Keyframe map[string]interface{}
+
+// Transition is markup which animates the element it is applied to when its
+// DOM node is created (including on the initial render), and when it is
+// removed by a re-render.
+//
+// Each transition is either a Web Animation, if keyframes are specified, or
+// else a CSS transition or animation driven by classes, if Name is specified.
+type Transition struct {
+	// Name is the prefix of the CSS classes applied to the element during its
+	// enter and leave transitions. For a name of "fade", entering:
+	//
+	// - "fade-enter-from" is applied before the element is inserted, and
+	//   removed the frame after it is inserted.
+	// - "fade-enter-active" is applied for the whole transition, and is where
+	//   the CSS transition or animation is declared.
+	// - "fade-enter-to" is applied when "fade-enter-from" is removed, until
+	//   the end of the transition.
+	//
+	// Leaving is the same, using "fade-leave-from", "fade-leave-active" and
+	// "fade-leave-to". The transition ends after the longest CSS transition or
+	// animation duration (plus delay) of the element.
+	Name string
+
+	// Enter and Leave are the keyframes of the Web Animations played when the
+	// element enters and leaves respectively. They take precedence over Name.
+	Enter, Leave []Keyframe
+
+	// Duration is the duration of Web Animations.
+	Duration time.Duration
+
+	// Easing is the timing function of Web Animations, e.g. "ease-in-out". If
+	// empty, it is "linear".
+	Easing string
+}
+
+// Apply implements the vecty.Applyer interface.
+func (t Transition) Apply(h *vecty.HTML) {
+	vecty.EnterLeave{Enter: t.enter, Leave: t.leave}.Apply(h)
+}
+
+// classes returns the class names applied during the given phase ("enter" or
+// "leave") of the transition.
+func (t Transition) classes(phase string) (from, active, to string) {
+	prefix := t.Name + "-" + phase + "-"
+	return prefix + "from", prefix + "active", prefix + "to"
+}
+
+// cssDuration returns the longest duration plus delay given the values of the
+// CSS transition-duration and transition-delay properties (or
+// animation-duration and animation-delay), which are lists of times such as
+// "0.3s, 200ms". Per CSS, the list of delays is repeated to match the list of
+// durations.
+func cssDuration(durations, delays string) time.Duration {
+	var (
+		ds  = parseTimes(durations)
+		dls = parseTimes(delays)
+		max time.Duration
+	)
+	for i, d := range ds {
+		if len(dls) > 0 {
+			d += dls[i%len(dls)]
+		}
+		if d > max {
+			max = d
+		}
+	}
+	return max
+}
+
+// parseTimes parses a comma-separated list of CSS times. Invalid times are
+// zero.
+func parseTimes(list string) []time.Duration {
+	if strings.TrimSpace(list) == "" {
+		return nil
+	}
+	var times []time.Duration
+	for _, s := range strings.Split(list, ",") {
+		s = strings.TrimSpace(s)
+		unit := time.Second
+		if strings.HasSuffix(s, "ms") {
+			s, unit = strings.TrimSuffix(s, "ms"), time.Millisecond
+		} else {
+			s = strings.TrimSuffix(s, "s")
+		}
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			v = 0
+		}
+		times = append(times, time.Duration(v*float64(unit)))
+	}
+	return times
+}
@@ -0,0 +1,116 @@
+// +build js
+
+package transition
+
+import (
+	"syscall/js"
+	"time"
+
+	"github.com/hexops/vecty"
+)
+
+// enter starts the enter transition of the element.
+func (t Transition) enter(h *vecty.HTML) {
+	switch {
+	case len(t.Enter) > 0:
+		t.animate(h.Node(), t.Enter, nil)
+	case t.Name != "":
+		t.transition(h.Node(), "enter", nil)
+	}
+}
+
+// leave starts the leave transition of the element, calling done once it
+// ends.
+func (t Transition) leave(h *vecty.HTML, done func()) {
+	switch {
+	case len(t.Leave) > 0:
+		t.animate(h.Node(), t.Leave, done)
+	case t.Name != "":
+		t.transition(h.Node(), "leave", done)
+	default:
+		done()
+	}
+}
+
+// animate plays a Web Animation of the node, calling done (if non-nil) once
+// it finishes or is cancelled.
+func (t Transition) animate(node js.Value, keyframes []Keyframe, done func()) {
+	frames := make([]interface{}, len(keyframes))
+	for i, k := range keyframes {
+		frames[i] = map[string]interface{}(k)
+	}
+	easing := t.Easing
+	if easing == "" {
+		easing = "linear"
+	}
+	options := map[string]interface{}{
+		"duration": float64(t.Duration) / float64(time.Millisecond),
+		"easing":   easing,
+	}
+	if done != nil {
+		// Hold the final keyframe until the node is removed.
+		options["fill"] = "forwards"
+	}
+	animation := node.Call("animate", frames, options)
+	if done == nil {
+		return
+	}
+	var finished js.Func
+	finished = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		finished.Release()
+		done()
+		return nil
+	})
+	animation.Set("onfinish", finished)
+	animation.Set("oncancel", finished)
+}
+
+// transition applies the classes of the given phase of the CSS transition to
+// the node, calling done (if non-nil) once it ends.
+func (t Transition) transition(node js.Value, phase string, done func()) {
+	from, active, to := t.classes(phase)
+	classList := node.Get("classList")
+	classList.Call("add", from, active)
+
+	// Wait for the styles of the first frame to be applied, or the transition
+	// would not start.
+	afterFrame(func() {
+		afterFrame(func() {
+			classList.Call("remove", from)
+			classList.Call("add", to)
+			style := js.Global().Call("getComputedStyle", node)
+			d := cssDuration(style.Get("transitionDuration").String(), style.Get("transitionDelay").String())
+			if a := cssDuration(style.Get("animationDuration").String(), style.Get("animationDelay").String()); a > d {
+				d = a
+			}
+			afterTimeout(d, func() {
+				classList.Call("remove", active, to)
+				if done != nil {
+					done()
+				}
+			})
+		})
+	})
+}
+
+// afterFrame calls f before the next repaint.
+func afterFrame(f func()) {
+	var cb js.Func
+	cb = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		cb.Release()
+		f()
+		return nil
+	})
+	js.Global().Call("requestAnimationFrame", cb)
+}
+
+// afterTimeout calls f after the given duration.
+func afterTimeout(d time.Duration, f func()) {
+	var cb js.Func
+	cb = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		cb.Release()
+		f()
+		return nil
+	})
+	js.Global().Call("setTimeout", cb, float64(d)/float64(time.Millisecond))
+}
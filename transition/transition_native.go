@@ -0,0 +1,16 @@
+// +build !js
+
+package transition
+
+import "github.com/hexops/vecty"
+
+// enter starts the enter transition of the element. Outside of a browser
+// there is nothing to animate, so it is no-op.
+func (t Transition) enter(h *vecty.HTML) {}
+
+// leave starts the leave transition of the element, calling done once it
+// ends. Outside of a browser there is nothing to animate, so done is called
+// immediately.
+func (t Transition) leave(h *vecty.HTML, done func()) {
+	done()
+}
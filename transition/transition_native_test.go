@@ -0,0 +1,15 @@
+// +build !js
+
+package transition
+
+import "testing"
+
+func TestTransition_native(t *testing.T) {
+	tr := Transition{Name: "fade"}
+	tr.enter(nil)
+	called := false
+	tr.leave(nil, func() { called = true })
+	if !called {
+		t.Fatal("got leave not done immediately")
+	}
+}
@@ -0,0 +1,34 @@
+package transition
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCSSDuration(t *testing.T) {
+	tests := []struct {
+		durations, delays string
+		want              time.Duration
+	}{
+		{"0s", "0s", 0},
+		{"", "", 0},
+		{"0.3s", "0s", 300 * time.Millisecond},
+		{"200ms", "100ms", 300 * time.Millisecond},
+		{"0.3s, 1s", "0s", time.Second},
+		{"1s, 0.5s", "0s, 1s", 1500 * time.Millisecond},
+		{"1s, 0.5s, 0.2s", "0.1s, 2s", 2500 * time.Millisecond},
+		{"invalid, 0.1s", "", 100 * time.Millisecond},
+	}
+	for _, tst := range tests {
+		if got := cssDuration(tst.durations, tst.delays); got != tst.want {
+			t.Errorf("cssDuration(%q, %q) = %v want %v", tst.durations, tst.delays, got, tst.want)
+		}
+	}
+}
+
+func TestTransition_classes(t *testing.T) {
+	from, active, to := Transition{Name: "fade"}.classes("leave")
+	if from != "fade-leave-from" || active != "fade-leave-active" || to != "fade-leave-to" {
+		t.Fatalf("got %q, %q, %q", from, active, to)
+	}
+}
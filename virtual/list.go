@@ -0,0 +1,364 @@
+// Package virtual provides components which render very large collections
+// efficiently, by only rendering the part of them that is visible.
+package virtual
+
+import (
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+	"github.com/hexops/vecty/event"
+	"github.com/hexops/vecty/style"
+)
+
+// DefaultOverscan is the number of rows rendered above and below the viewport
+// of a List whose Overscan is zero.
+const DefaultOverscan = 5
+
+// DefaultEstimatedRowHeight is the height in pixels assumed for rows of a List
+// before any is measured, if its EstimatedRowHeight is zero.
+const DefaultEstimatedRowHeight = 20
+
+// List is a scrollable list which only renders the rows in (and near) its
+// viewport, such that even lists of many thousands of rows render quickly and
+// create few DOM nodes:
+//
+// 	&virtual.List{
+// 		Len:       len(lines),
+// 		Height:    600,
+// 		RowHeight: 20,
+// 		Row: func(i int) vecty.ComponentOrHTML {
+// 			return elem.Div(vecty.Text(lines[i]))
+// 		},
+// 	}
+//
+// Rows are either of a fixed height (RowHeight), or measured once rendered,
+// which is slower but supports rows of different heights.
+//
+// Each rendered row is wrapped in a keyed element, so that scrolling reuses
+// the DOM nodes of rows which remain visible, and rows keep their DOM nodes
+// (and the state of their components) when rows are inserted or removed
+// before them.
+type List struct {
+	vecty.Core
+
+	// Len is the number of rows.
+	Len int `vecty:"prop"`
+
+	// Row renders the row at the given index. It is only called for the rows
+	// which are rendered.
+	Row func(i int) vecty.ComponentOrHTML `vecty:"prop"`
+
+	// Key returns the key of the row at the given index, which identifies it
+	// amongst the other rows. If nil, the key of the rendered row is used if
+	// it implements vecty.Keyer (e.g. an element with vecty.Key markup), or
+	// else its index.
+	Key func(i int) interface{} `vecty:"prop"`
+
+	// Height is the height of the viewport in pixels. If zero, its height must
+	// be set via CSS (e.g. using Markup), and is measured once rendered.
+	Height float64 `vecty:"prop"`
+
+	// RowHeight is the height of every row in pixels. If zero, rows are
+	// measured once rendered, and EstimatedRowHeight is used for rows which
+	// have not been rendered yet.
+	RowHeight float64 `vecty:"prop"`
+
+	// EstimatedRowHeight is the height assumed for rows which have not been
+	// measured yet, in pixels. If zero, the average height of the rows
+	// measured so far is used, or DefaultEstimatedRowHeight until a row is
+	// measured.
+	EstimatedRowHeight float64 `vecty:"prop"`
+
+	// Overscan is the number of rows rendered above and below the viewport, so
+	// that they are visible as soon as scrolled to. If zero, DefaultOverscan
+	// is used.
+	Overscan int `vecty:"prop"`
+
+	// Markup is applied to the viewport element, e.g. to style it.
+	Markup []vecty.Applyer `vecty:"prop"`
+
+	viewport, rows vecty.Ref
+	scrollTop      float64
+	viewportHeight float64
+	start, end     int
+
+	// keys are the keys of the rendered rows.
+	keys []interface{}
+
+	// measured are the measured heights of rows by key, such that rows keep
+	// their height when rows are inserted or removed before them.
+	// measuredSum is the sum of the heights, and measuredCount their count.
+	measured      map[interface{}]float64
+	measuredSum   float64
+	measuredCount int
+
+	// heights are the heights of the rows by index, zero for rows whose
+	// height is unknown, and tree sums them such that the offset of any row
+	// is found without visiting the rows before it.
+	heights []float64
+	tree    heightTree
+
+	// stale reports whether heights changed whilst rendering, such that the
+	// rendered rows and their offset must be updated.
+	stale bool
+}
+
+// Render implements the vecty.Component interface.
+func (l *List) Render() vecty.ComponentOrHTML {
+	if l.RowHeight == 0 && len(l.heights) != l.Len {
+		l.resize()
+	}
+	var offset, total float64
+	l.start, l.end, offset, total = l.visibleRange()
+
+	rows := make(vecty.List, 0, l.end-l.start)
+	l.keys = l.keys[:0]
+	for i := l.start; i < l.end; i++ {
+		row := l.Row(i)
+		key := l.key(i, row)
+		if h, ok := l.measured[key]; ok && l.RowHeight == 0 && h != l.heights[i] {
+			// The row was measured at another index.
+			l.setHeight(i, h)
+			l.stale = true
+		}
+		l.keys = append(l.keys, key)
+		rows = append(rows, elem.Div(vecty.Markup(vecty.Key(key)), row))
+	}
+
+	return elem.Div(
+		vecty.Markup(
+			&l.viewport,
+			style.OverflowY(style.OverflowAuto),
+			vecty.MarkupIf(l.Height > 0, style.Height(px(l.Height))),
+			event.Scroll(l.onScroll),
+			vecty.Markup(l.Markup...),
+		),
+		elem.Div(
+			vecty.Markup(
+				vecty.Style("position", "relative"),
+				style.Height(px(total)),
+			),
+			elem.Div(
+				vecty.Markup(
+					&l.rows,
+					vecty.Style("transform", "translateY("+string(px(offset))+")"),
+				),
+				rows,
+			),
+		),
+	)
+}
+
+// Mount implements the vecty.Mounter interface.
+func (l *List) Mount() {
+	l.measure()
+}
+
+// Updated implements the vecty.Updater interface.
+func (l *List) Updated(prev vecty.Component) {
+	l.measure()
+}
+
+// onScroll re-renders the list if different rows become visible.
+func (l *List) onScroll(e *vecty.Event) {
+	l.scrollTop = e.Target.Get("scrollTop").Float()
+	if start, end, _, _ := l.visibleRange(); start != l.start || end != l.end {
+		vecty.Rerender(l)
+	}
+}
+
+// measure measures the viewport and rendered rows as needed, and re-renders
+// the list if their size changed.
+func (l *List) measure() {
+	if !l.viewport.Attached() || !l.rows.Attached() {
+		return
+	}
+	changed := l.stale
+	l.stale = false
+	if l.Height == 0 {
+		if h := l.viewport.Node().Get("clientHeight").Float(); h != l.viewportHeight {
+			l.viewportHeight = h
+			changed = true
+		}
+	}
+	if l.RowHeight == 0 && len(l.heights) == l.Len {
+		children := l.rows.Node().Get("children")
+		for i := l.start; i < l.end; i++ {
+			h := children.Call("item", i-l.start).Call("getBoundingClientRect").Get("height").Float()
+			l.setMeasured(l.keys[i-l.start], h)
+			if h != l.heights[i] {
+				l.setHeight(i, h)
+				changed = true
+			}
+		}
+	}
+	if changed {
+		vecty.Rerender(l)
+	}
+}
+
+// resize resizes the heights to the number of rows. As rows may have been
+// inserted or removed anywhere, the heights of all rows become unknown until
+// they are rendered again, and found amongst the measured heights by key.
+func (l *List) resize() {
+	l.heights = make([]float64, l.Len)
+	l.tree = newHeightTree(l.Len)
+}
+
+// setHeight sets the height of the row at the given index.
+func (l *List) setHeight(i int, h float64) {
+	count := 0
+	switch {
+	case l.heights[i] == 0 && h != 0:
+		count = 1
+	case l.heights[i] != 0 && h == 0:
+		count = -1
+	}
+	l.tree.add(i, h-l.heights[i], count)
+	l.heights[i] = h
+}
+
+// setMeasured records the measured height of the row with the given key.
+func (l *List) setMeasured(key interface{}, h float64) {
+	if prev, ok := l.measured[key]; ok {
+		l.measuredSum -= prev
+		l.measuredCount--
+	}
+	if l.measured == nil {
+		l.measured = make(map[interface{}]float64)
+	}
+	l.measured[key] = h
+	l.measuredSum += h
+	l.measuredCount++
+}
+
+// key returns the key of the given row at the given index.
+func (l *List) key(i int, row vecty.ComponentOrHTML) interface{} {
+	if l.Key != nil {
+		return l.Key(i)
+	}
+	if k, ok := row.(vecty.Keyer); ok {
+		if key := k.Key(); key != nil {
+			return key
+		}
+	}
+	return i
+}
+
+// visibleRange returns the rows to render, the offset of the first one, and
+// the height of all rows.
+func (l *List) visibleRange() (start, end int, offset, total float64) {
+	viewportHeight := l.Height
+	if viewportHeight == 0 {
+		viewportHeight = l.viewportHeight
+	}
+	overscan := l.Overscan
+	if overscan == 0 {
+		overscan = DefaultOverscan
+	}
+	if l.RowHeight > 0 {
+		return fixedRange(l.Len, l.RowHeight, l.scrollTop, viewportHeight, overscan)
+	}
+	return visibleRange(l.Len, l.offset, l.scrollTop, viewportHeight, overscan)
+}
+
+// offset returns the offset of the row at the given index (or the height of
+// all rows, given the number of rows), using the estimated height of the rows
+// which have not been measured.
+func (l *List) offset(i int) float64 {
+	sum, count := l.tree.prefix(i)
+	return sum + float64(i-count)*l.estimatedRowHeight()
+}
+
+// estimatedRowHeight returns the height assumed for rows which have not been
+// measured yet.
+func (l *List) estimatedRowHeight() float64 {
+	switch {
+	case l.EstimatedRowHeight > 0:
+		return l.EstimatedRowHeight
+	case l.measuredCount > 0:
+		return l.measuredSum / float64(l.measuredCount)
+	}
+	return DefaultEstimatedRowHeight
+}
+
+// heightTree is a Fenwick tree of the heights of rows, which sums the heights
+// of the rows before any index, and updates the height of a row, in O(log n).
+type heightTree struct {
+	sums   []float64
+	counts []int
+}
+
+// newHeightTree returns a heightTree of n rows of unknown height.
+func newHeightTree(n int) heightTree {
+	return heightTree{sums: make([]float64, n+1), counts: make([]int, n+1)}
+}
+
+// add adds height to the height of the row at index i, and count to the
+// number of rows of known height.
+func (t heightTree) add(i int, height float64, count int) {
+	for i++; i < len(t.sums); i += i & -i {
+		t.sums[i] += height
+		t.counts[i] += count
+	}
+}
+
+// prefix returns the sum of the heights of the rows before index i, and the
+// number of those of known height.
+func (t heightTree) prefix(i int) (sum float64, count int) {
+	for ; i > 0; i -= i & -i {
+		sum += t.sums[i]
+		count += t.counts[i]
+	}
+	return sum, count
+}
+
+// visibleRange returns the range [start, end) of the n rows which are visible
+// in a viewport of the given height scrolled to scrollTop, extended by
+// overscan rows on each side, given the offset of each row (and of the end of
+// the last row, at index n). It also returns the offset of the start row, and
+// the height of all rows.
+func visibleRange(n int, offset func(i int) float64, scrollTop, viewportHeight float64, overscan int) (start, end int, startOffset, total float64) {
+	first := sort.Search(n, func(i int) bool { return offset(i+1) > scrollTop })
+	last := sort.Search(n, func(i int) bool { return offset(i) >= scrollTop+viewportHeight })
+	start, end = overscanRange(n, first, last, overscan)
+	return start, end, offset(start), offset(n)
+}
+
+// fixedRange is visibleRange for rows which all have the given height.
+func fixedRange(n int, rowHeight, scrollTop, viewportHeight float64, overscan int) (start, end int, offset, total float64) {
+	first := clamp(int(scrollTop/rowHeight), n)
+	last := clamp(int(math.Ceil((scrollTop+viewportHeight)/rowHeight)), n)
+	start, end = overscanRange(n, first, last, overscan)
+	return start, end, float64(start) * rowHeight, float64(n) * rowHeight
+}
+
+// overscanRange returns the range [start, end) of the n rows which extends the
+// range of visible rows [first, last) by overscan rows on each side. If the
+// viewport is scrolled past the end (e.g. because rows were removed), first is
+// n.
+func overscanRange(n, first, last, overscan int) (start, end int) {
+	if last < first {
+		last = first
+	}
+	return clamp(first-overscan, n), clamp(last+overscan, n)
+}
+
+// clamp returns i clamped to the range [0, n].
+func clamp(i, n int) int {
+	switch {
+	case i < 0:
+		return 0
+	case i > n:
+		return n
+	}
+	return i
+}
+
+// px returns the size in pixels.
+func px(pixels float64) style.Size {
+	return style.Size(strconv.FormatFloat(pixels, 'f', -1, 64) + "px")
+}
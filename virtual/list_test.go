@@ -0,0 +1,120 @@
+package virtual
+
+import (
+	"testing"
+
+	"github.com/hexops/vecty"
+	"github.com/hexops/vecty/elem"
+)
+
+func TestVisibleRange(t *testing.T) {
+	fixed := func(i int) float64 { return float64(10 * i) }
+	varying := func(i int) float64 { return float64(15*i - 5*(i%2)) } // rows of 10, 20, 10, 20, ...
+	tests := []struct {
+		name                  string
+		n                     int
+		offset                func(i int) float64
+		rowHeight             float64
+		scrollTop, height     float64
+		overscan              int
+		wantStart, wantEnd    int
+		wantOffset, wantTotal float64
+	}{
+		{"top", 100, fixed, 10, 0, 50, 0, 0, 5, 0, 1000},
+		{"top overscan", 100, fixed, 10, 0, 50, 2, 0, 7, 0, 1000},
+		{"middle", 100, fixed, 10, 205, 50, 0, 20, 26, 200, 1000},
+		{"middle overscan", 100, fixed, 10, 205, 50, 2, 18, 28, 180, 1000},
+		{"bottom", 100, fixed, 10, 950, 50, 2, 93, 100, 930, 1000},
+		{"past end", 10, fixed, 10, 500, 50, 2, 8, 10, 80, 100},
+		{"varying", 10, varying, 0, 35, 30, 0, 2, 5, 30, 150},
+		{"empty", 0, fixed, 10, 0, 50, 2, 0, 0, 0, 0},
+		{"no viewport", 100, fixed, 10, 0, 0, 2, 0, 2, 0, 1000},
+	}
+	for _, tst := range tests {
+		t.Run(tst.name, func(t *testing.T) {
+			start, end, offset, total := visibleRange(tst.n, tst.offset, tst.scrollTop, tst.height, tst.overscan)
+			if start != tst.wantStart || end != tst.wantEnd || offset != tst.wantOffset || total != tst.wantTotal {
+				t.Fatalf("got (%v, %v, %v, %v) want (%v, %v, %v, %v)", start, end, offset, total, tst.wantStart, tst.wantEnd, tst.wantOffset, tst.wantTotal)
+			}
+			if tst.rowHeight == 0 {
+				return
+			}
+			start, end, offset, total = fixedRange(tst.n, tst.rowHeight, tst.scrollTop, tst.height, tst.overscan)
+			if start != tst.wantStart || end != tst.wantEnd || offset != tst.wantOffset || total != tst.wantTotal {
+				t.Fatalf("fixedRange: got (%v, %v, %v, %v) want (%v, %v, %v, %v)", start, end, offset, total, tst.wantStart, tst.wantEnd, tst.wantOffset, tst.wantTotal)
+			}
+		})
+	}
+}
+
+func TestList_key(t *testing.T) {
+	l := &List{}
+	if key := l.key(3, elem.Div()); key != 3 {
+		t.Fatalf("got %v want index key", key)
+	}
+	if key := l.key(3, elem.Div(vecty.Markup(vecty.Key("a")))); key != "a" {
+		t.Fatalf("got %v want Keyer key", key)
+	}
+	l.Key = func(i int) interface{} { return i * 2 }
+	if key := l.key(3, elem.Div(vecty.Markup(vecty.Key("a")))); key != 6 {
+		t.Fatalf("got %v want Key func key", key)
+	}
+}
+
+func TestList_offset(t *testing.T) {
+	l := &List{Len: 10}
+	l.resize()
+	if got := l.offset(10); got != 10*DefaultEstimatedRowHeight {
+		t.Fatalf("got %v want %v", got, 10*DefaultEstimatedRowHeight)
+	}
+
+	// Unmeasured rows are of the average height of the measured rows.
+	l.setMeasured(2, 10)
+	l.setHeight(2, 10)
+	l.setMeasured(5, 50)
+	l.setHeight(5, 50)
+	if got := l.offset(3); got != 2*30+10 {
+		t.Fatalf("got %v want 70", got)
+	}
+	if got := l.offset(10); got != 8*30+10+50 {
+		t.Fatalf("got %v want 300", got)
+	}
+
+	// Measuring a row again replaces its height.
+	l.setMeasured(5, 30)
+	l.setHeight(5, 30)
+	if got := l.offset(10); got != 8*20+10+30 {
+		t.Fatalf("got %v want 200", got)
+	}
+
+	l.EstimatedRowHeight = 5
+	if got := l.offset(6); got != 4*5+10+30 {
+		t.Fatalf("got %v want 60", got)
+	}
+}
+
+func TestList_measuredByKey(t *testing.T) {
+	keys := []interface{}{"a", "b", "c"}
+	l := &List{
+		Height: 1000,
+		Row:    func(i int) vecty.ComponentOrHTML { return elem.Div() },
+		Key:    func(i int) interface{} { return keys[i] },
+	}
+	l.Len = len(keys)
+	l.setMeasured("b", 50)
+	l.Render()
+	if l.heights[1] != 50 || !l.stale {
+		t.Fatalf("got heights %v, stale %v; want the height of b at index 1", l.heights, l.stale)
+	}
+
+	// Rows keep their height when a row is inserted before them.
+	keys = append([]interface{}{"x"}, keys...)
+	l.Len = len(keys)
+	l.Render()
+	if l.heights[1] != 0 || l.heights[2] != 50 {
+		t.Fatalf("got heights %v, want the height of b at index 2", l.heights)
+	}
+	if got := l.offset(4); got != 3*50+50 {
+		t.Fatalf("got %v want 200", got)
+	}
+}
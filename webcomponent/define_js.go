@@ -0,0 +1,148 @@
+// +build js
+
+package webcomponent
+
+import (
+	"syscall/js"
+
+	"github.com/hexops/vecty"
+)
+
+// instanceKey is the property of an element holding the ID of its instance.
+const instanceKey = "__vectyInstance"
+
+// Define registers a custom element with the given name, which must contain a
+// hyphen (e.g. "my-widget"). Each element renders an instance of the component
+// returned by newComponent, which must be a pointer to a struct.
+//
+// Define panics if the name is invalid, or already defined.
+func Define(name string, newComponent func() vecty.Component) {
+	if !validName(name) {
+		panic("webcomponent: invalid custom element name " + name + " (must be lowercase and contain a hyphen)")
+	}
+	customElements := js.Global().Get("customElements")
+	if customElements.Call("get", name).Truthy() {
+		panic("webcomponent: custom element " + name + " is already defined")
+	}
+	attrs := attributes(newComponent())
+	observed := make([]interface{}, len(attrs))
+	for i, a := range attrs {
+		observed[i] = a.name
+	}
+
+	// The callbacks are never released, as elements may be created for as long
+	// as the page is open.
+	var (
+		instances = make(map[int]vecty.Component)
+		nextID    int
+	)
+	instance := func(el js.Value) (vecty.Component, int) {
+		id := el.Get(instanceKey)
+		if id.IsUndefined() {
+			return nil, 0
+		}
+		return instances[id.Int()], id.Int()
+	}
+	class, proto := elementClass(observed)
+	proto.Set("connectedCallback", js.FuncOf(func(el js.Value, args []js.Value) interface{} {
+		if c, _ := instance(el); c != nil {
+			return nil
+		}
+		c := newComponent()
+		for _, a := range attrs {
+			setAttribute(c, a, el.Call("getAttribute", a.name))
+		}
+		nextID++
+		instances[nextID] = c
+		el.Set(instanceKey, nextID)
+		if err := vecty.RenderIntoContainerNode(el, c); err != nil {
+			panic(err)
+		}
+		return nil
+	}))
+	proto.Set("disconnectedCallback", js.FuncOf(func(el js.Value, args []js.Value) interface{} {
+		// The element may be removed by the render of another component, during
+		// which the component cannot be unrendered, so it is torn down once the
+		// render completes; unless the element was merely moved, and is thus
+		// connected again by then.
+		queueMicrotask(func() {
+			if el.Get("isConnected").Bool() {
+				return
+			}
+			c, id := instance(el)
+			if c == nil {
+				return
+			}
+			delete(instances, id)
+			el.Delete(instanceKey)
+			vecty.Unrender(c)
+		})
+		return nil
+	}))
+	proto.Set("attributeChangedCallback", js.FuncOf(func(el js.Value, args []js.Value) interface{} {
+		name, value := args[0].String(), args[2]
+		c, _ := instance(el)
+		if c == nil {
+			// Attributes are read once connected.
+			return nil
+		}
+		for _, a := range attrs {
+			if a.name != name {
+				continue
+			}
+			setAttribute(c, a, value)
+			vecty.Rerender(c)
+		}
+		return nil
+	}))
+
+	customElements.Call("define", name, class)
+}
+
+// elementClass returns the class of a custom element observing the given
+// attributes, along with its prototype for its lifecycle callbacks to be set
+// on. Custom elements must be classes extending HTMLElement, which would
+// require evaluating source (breaking pages whose Content Security Policy
+// forbids eval), so it is instead a constructor function which constructs an
+// HTMLElement as if it were a class extending it.
+func elementClass(observed []interface{}) (class, proto js.Value) {
+	htmlElement := js.Global().Get("HTMLElement")
+	// The constructor is never released, as elements may be created for as
+	// long as the page is open.
+	var constructor js.Func
+	constructor = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		return js.Global().Get("Reflect").Call("construct", htmlElement, []interface{}{}, constructor)
+	})
+	object := js.Global().Get("Object")
+	proto = object.Call("create", htmlElement.Get("prototype"))
+	object.Call("defineProperty", proto, "constructor", map[string]interface{}{
+		"value":        constructor,
+		"writable":     true,
+		"configurable": true,
+	})
+	constructor.Set("prototype", proto)
+	constructor.Set("observedAttributes", observed)
+	object.Call("setPrototypeOf", constructor, htmlElement)
+	return constructor.Value, proto
+}
+
+// setAttribute sets the field of the component mapped to the attribute, given
+// the attribute's value (null if absent).
+func setAttribute(c vecty.Component, a attribute, value js.Value) {
+	if value.IsNull() {
+		a.set(c, "", false)
+		return
+	}
+	a.set(c, value.String(), true)
+}
+
+// queueMicrotask calls f once the JavaScript currently running completes.
+func queueMicrotask(f func()) {
+	var cb js.Func
+	cb = js.FuncOf(func(this js.Value, args []js.Value) interface{} {
+		cb.Release()
+		f()
+		return nil
+	})
+	js.Global().Call("queueMicrotask", cb)
+}
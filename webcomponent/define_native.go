@@ -0,0 +1,18 @@
+// +build !js
+
+package webcomponent
+
+import "github.com/hexops/vecty"
+
+// Define registers a custom element with the given name, which must contain a
+// hyphen (e.g. "my-widget"). Each element renders an instance of the component
+// returned by newComponent, which must be a pointer to a struct.
+//
+// Define panics if the name is invalid, or already defined.
+//
+// It is declared here just for purposes of testing under native 'go test',
+// linting, and serving documentation under godoc.org; outside of a browser it
+// panics.
+func Define(name string, newComponent func() vecty.Component) {
+	panic("webcomponent: Define is only supported when running inside a browser")
+}
@@ -0,0 +1,152 @@
+// Package webcomponent registers Vecty components as custom elements (Web
+// Components), so that they can be used as plain HTML tags, including from
+// applications built with other frameworks:
+//
+// 	type Greeting struct {
+// 		vecty.Core
+// 		Name    string `vecty:"prop"`
+// 		Excited bool   `vecty:"prop"`
+// 	}
+//
+// 	func main() {
+// 		webcomponent.Define("my-greeting", func() vecty.Component {
+// 			return &Greeting{}
+// 		})
+// 		select {}
+// 	}
+//
+// Which is then used as:
+//
+// 	<my-greeting name="World" excited></my-greeting>
+//
+// Each element renders its own instance of the component as its content. The
+// component is rendered (and thus mounted) when the element is inserted into
+// the document, and unrendered (and thus unmounted) shortly after it is
+// removed, in a microtask: this allows the element to be removed by the render
+// of another Vecty component, and to be moved without being unrendered.
+//
+// Attributes
+//
+// The `vecty:"prop"` fields of the component of type string, bool, or of a
+// numeric kind are observed attributes of the element: whenever the attribute
+// changes, the field is set and the component is re-rendered.
+//
+// The attribute name is the field name in kebab-case (e.g. "max-items" for a
+// field named MaxItems), unless specified using an `attr:"name"` tag. A bool
+// field is true when the attribute is present, whatever its value, whilst a
+// numeric field is zero when the attribute is absent or not a valid number
+// (including out of range). Other `vecty:"prop"` fields are not observed,
+// unless tagged with `attr`, which is an error.
+package webcomponent
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/hexops/vecty"
+)
+
+// attribute is an observed attribute of a custom element, mapped to a
+// `vecty:"prop"` field of its component.
+type attribute struct {
+	name  string
+	field int
+}
+
+// attributes returns the attributes observed for the given component, which
+// must be a pointer to a struct.
+func attributes(c vecty.Component) []attribute {
+	t := reflect.TypeOf(c)
+	if t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic("webcomponent: component must be a pointer to a struct, found " + t.String())
+	}
+	t = t.Elem()
+	var attrs []attribute
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("vecty") != "prop" {
+			continue
+		}
+		name, tagged := f.Tag.Lookup("attr")
+		if !supported(f.Type.Kind()) {
+			if tagged {
+				panic("webcomponent: unsupported type " + f.Type.String() + " for attribute of field " + t.String() + "." + f.Name)
+			}
+			continue
+		}
+		if name == "" {
+			name = kebabCase(f.Name)
+		}
+		attrs = append(attrs, attribute{name: name, field: i})
+	}
+	return attrs
+}
+
+// supported reports whether fields of the given kind may be set from
+// attributes.
+func supported(k reflect.Kind) bool {
+	switch k {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// set sets the field of the component to the value of the attribute, or its
+// absence if present is false.
+func (a attribute) set(c vecty.Component, value string, present bool) {
+	f := reflect.ValueOf(c).Elem().Field(a.field)
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(value)
+	case reflect.Bool:
+		f.SetBool(present)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, f.Type().Bits())
+		if err != nil {
+			n = 0
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, f.Type().Bits())
+		if err != nil {
+			n = 0
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(strings.TrimSpace(value), f.Type().Bits())
+		if err != nil {
+			n = 0
+		}
+		f.SetFloat(n)
+	}
+}
+
+// kebabCase returns the Go identifier in kebab-case, e.g. "max-items" for
+// MaxItems and "html-content" for HTMLContent.
+func kebabCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if i > 0 && unicode.IsUpper(r) {
+			prev := runes[i-1]
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextLower) {
+				b.WriteByte('-')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
+
+// validName reports whether the name is valid for a custom element, which
+// must start with a lowercase ASCII letter and contain a hyphen.
+func validName(name string) bool {
+	return name != "" && name[0] >= 'a' && name[0] <= 'z' && strings.Contains(name, "-") && strings.ToLower(name) == name
+}
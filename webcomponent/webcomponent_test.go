@@ -0,0 +1,101 @@
+package webcomponent
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/hexops/vecty"
+)
+
+type widget struct {
+	vecty.Core
+	Title    string            `vecty:"prop"`
+	Open     bool              `vecty:"prop"`
+	MaxItems int               `vecty:"prop"`
+	Ratio    float64           `vecty:"prop" attr:"aspect-ratio"`
+	Count    uint8             `vecty:"prop"`
+	OnSelect func(item string) `vecty:"prop"`
+	internal string
+}
+
+func (w *widget) Render() vecty.ComponentOrHTML { return nil }
+
+type badWidget struct {
+	vecty.Core
+	Items []string `vecty:"prop" attr:"items"`
+}
+
+func (w *badWidget) Render() vecty.ComponentOrHTML { return nil }
+
+func TestAttributes(t *testing.T) {
+	var names []string
+	for _, a := range attributes(&widget{}) {
+		names = append(names, a.name)
+	}
+	want := []string{"title", "open", "max-items", "aspect-ratio", "count"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("got attributes %v want %v", names, want)
+	}
+
+	defer func() {
+		want := "webcomponent: unsupported type []string for attribute of field webcomponent.badWidget.Items"
+		if got := recover(); got != want {
+			t.Fatalf("got panic %v want %q", got, want)
+		}
+	}()
+	attributes(&badWidget{})
+}
+
+func TestAttribute_set(t *testing.T) {
+	w := &widget{}
+	attrs := attributes(w)
+	values := map[string]string{
+		"title":        "Hello",
+		"open":         "",
+		"max-items":    " 10 ",
+		"aspect-ratio": "1.5",
+		"count":        "300", // out of range
+	}
+	for _, a := range attrs {
+		a.set(w, values[a.name], true)
+	}
+	if want := (widget{Title: "Hello", Open: true, MaxItems: 10, Ratio: 1.5}); !reflect.DeepEqual(*w, want) {
+		t.Fatalf("got %+v want %+v", *w, want)
+	}
+
+	for _, a := range attrs {
+		a.set(w, "", false)
+	}
+	if want := (widget{}); !reflect.DeepEqual(*w, want) {
+		t.Fatalf("got %+v after removing attributes, want %+v", *w, want)
+	}
+}
+
+func TestKebabCase(t *testing.T) {
+	tests := map[string]string{
+		"Title":       "title",
+		"MaxItems":    "max-items",
+		"HTMLContent": "html-content",
+		"ID":          "id",
+		"Item2Name":   "item2-name",
+	}
+	for name, want := range tests {
+		if got := kebabCase(name); got != want {
+			t.Errorf("kebabCase(%q) = %q want %q", name, got, want)
+		}
+	}
+}
+
+func TestValidName(t *testing.T) {
+	for name, want := range map[string]bool{
+		"my-widget": true,
+		"widget":    false,
+		"My-Widget": false,
+		"-widget":   false,
+		"":          false,
+	} {
+		if got := validName(name); got != want {
+			t.Errorf("validName(%q) = %v want %v", name, got, want)
+		}
+	}
+}